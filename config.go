@@ -4,15 +4,37 @@
 package leaderreceivercreator
 
 import (
+	"errors"
 	"fmt"
+	"sort"
+	"time"
 
+	"github.com/cenkalti/backoff/v4"
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/confmap"
+
+	"github.com/skhalash/leaderreceivercreator/internal/leaderelection"
+	"github.com/skhalash/leaderreceivercreator/internal/metadata"
 )
 
 const (
 	// receiversConfigKey is the config key name used to specify the subreceivers.
 	subreceiverConfigKey = "receiver"
+
+	// RenewFailureStop stops the subreceivers as soon as the leadership is lost.
+	RenewFailureStop = "stop"
+	// RenewFailureKeepRunning keeps the subreceivers running for a grace period after the leadership is lost
+	// because the lease could not be renewed.
+	RenewFailureKeepRunning = "keep_running"
+	// RenewFailureRunEverywhere runs the subreceivers on every replica while the leadership cannot be
+	// established because the backend cannot be reached.
+	RenewFailureRunEverywhere = "run_everywhere"
+
+	defaultRenewFailureGracePeriod = time.Minute
+
+	defaultRestartInitialInterval = time.Second
+	defaultRestartMaxInterval     = 30 * time.Second
+	defaultRestartMaxFailures     = 5
 )
 
 // receiverConfig describes a receiver instance with a default config.
@@ -37,11 +59,90 @@ func newReceiverConfig(name string, cfg map[string]any) (receiverConfig, error)
 	}, nil
 }
 
-var _ confmap.Unmarshaler = (*Config)(nil)
+var (
+	_ confmap.Unmarshaler       = (*Config)(nil)
+	_ component.ConfigValidator = (*Config)(nil)
+)
 
 // Config defines configuration for receiver_creator.
 type Config struct {
-	subreceiverConfig receiverConfig
+	LeaderElection leaderelection.Config `mapstructure:"leader_election"`
+	// LeaderElector is the ID of a leader_elector extension whose election gates the subreceivers.
+	// If it is set, the leader_election section is ignored.
+	LeaderElector *component.ID `mapstructure:"leader_elector"`
+	// OnRenewFailure defines what happens to the subreceivers when the leadership is lost because the
+	// lease could not be renewed.
+	OnRenewFailure RenewFailureConfig `mapstructure:"on_renew_failure"`
+	// RestartOnFailure defines how the subreceivers are restarted when they fail to start.
+	RestartOnFailure RestartConfig `mapstructure:"restart_on_failure"`
+
+	// subreceiverConfigs are sorted by id, so that the subreceivers are started in a stable order.
+	subreceiverConfigs []receiverConfig
+}
+
+// RenewFailureConfig defines the policy applied when the lease cannot be renewed.
+type RenewFailureConfig struct {
+	// Policy is one of stop, keep_running and run_everywhere.
+	Policy string `mapstructure:"policy"`
+	// GracePeriod is how long the subreceivers keep running with the keep_running policy.
+	GracePeriod time.Duration `mapstructure:"grace_period"`
+}
+
+func (cfg *RenewFailureConfig) validate() error {
+	switch cfg.Policy {
+	case RenewFailureStop, RenewFailureRunEverywhere:
+		return nil
+	case RenewFailureKeepRunning:
+		if cfg.GracePeriod <= 0 {
+			return fmt.Errorf("on_renew_failure::grace_period (%v) must be positive", cfg.GracePeriod)
+		}
+		return nil
+	}
+	return fmt.Errorf("unsupported on_renew_failure::policy %q", cfg.Policy)
+}
+
+// RestartConfig defines the exponential backoff between the attempts to start the subreceivers.
+type RestartConfig struct {
+	// InitialInterval is the time to wait after the first failure.
+	InitialInterval time.Duration `mapstructure:"initial_interval"`
+	// MaxInterval is the upper bound of the time to wait between the attempts.
+	MaxInterval time.Duration `mapstructure:"max_interval"`
+	// MaxFailures is the number of consecutive failures after which the leader steps down, so that another
+	// replica can try. If it is 0, the leader keeps retrying.
+	MaxFailures int `mapstructure:"max_failures"`
+}
+
+func (cfg *RestartConfig) validate() error {
+	if cfg.InitialInterval <= 0 || cfg.MaxInterval <= 0 {
+		return errors.New("restart_on_failure::initial_interval and restart_on_failure::max_interval must be positive")
+	}
+	if cfg.InitialInterval > cfg.MaxInterval {
+		return fmt.Errorf("restart_on_failure::initial_interval (%v) must not be greater than restart_on_failure::max_interval (%v)",
+			cfg.InitialInterval, cfg.MaxInterval)
+	}
+	if cfg.MaxFailures < 0 {
+		return fmt.Errorf("restart_on_failure::max_failures (%d) must not be negative", cfg.MaxFailures)
+	}
+	return nil
+}
+
+// newBackOff returns an exponential backoff with jitter that never gives up.
+func (cfg *RestartConfig) newBackOff() backoff.BackOff {
+	b := backoff.NewExponentialBackOff()
+	b.InitialInterval = cfg.InitialInterval
+	b.MaxInterval = cfg.MaxInterval
+	b.MaxElapsedTime = 0
+	b.Reset()
+	return b
+}
+
+// subreceiverIDs returns the ids of the subreceivers.
+func (cfg *Config) subreceiverIDs() []component.ID {
+	ids := make([]component.ID, 0, len(cfg.subreceiverConfigs))
+	for _, subreceiver := range cfg.subreceiverConfigs {
+		ids = append(ids, subreceiver.id)
+	}
+	return ids
 }
 
 func (cfg *Config) Unmarshal(componentParser *confmap.Conf) error {
@@ -65,13 +166,44 @@ func (cfg *Config) Unmarshal(componentParser *confmap.Conf) error {
 			return fmt.Errorf("unable to extract subreceiver key %v: %w", subreceiverKey, err)
 		}
 
-		cfg.subreceiverConfig, err = newReceiverConfig(subreceiverKey, receiverConfig.ToStringMap())
+		subreceiver, err := newReceiverConfig(subreceiverKey, receiverConfig.ToStringMap())
 		if err != nil {
-			return fmt.Errorf("failed to create subreceiver config: %w", err)
+			return fmt.Errorf("%s%s%s: %w", subreceiverConfigKey, confmap.KeyDelimiter, subreceiverKey, err)
 		}
+		cfg.subreceiverConfigs = append(cfg.subreceiverConfigs, subreceiver)
+	}
 
-		return nil
+	sort.Slice(cfg.subreceiverConfigs, func(i, j int) bool {
+		return cfg.subreceiverConfigs[i].id.String() < cfg.subreceiverConfigs[j].id.String()
+	})
+
+	return nil
+}
+
+// Validate checks the subreceivers, the renewal failure policy and the restarts. The leader election settings are validated
+// by component.ValidateConfig.
+func (cfg *Config) Validate() error {
+	if err := cfg.OnRenewFailure.validate(); err != nil {
+		return err
+	}
+	if err := cfg.RestartOnFailure.validate(); err != nil {
+		return err
 	}
+	if len(cfg.subreceiverConfigs) == 0 {
+		return fmt.Errorf("%s: at least one subreceiver must be configured", subreceiverConfigKey)
+	}
+
+	seen := make(map[component.ID]bool, len(cfg.subreceiverConfigs))
+	for _, subreceiver := range cfg.subreceiverConfigs {
+		path := subreceiverConfigKey + confmap.KeyDelimiter + subreceiver.id.String()
+		if seen[subreceiver.id] {
+			return fmt.Errorf("%s: subreceiver is configured more than once", path)
+		}
+		seen[subreceiver.id] = true
 
+		if subreceiver.id.Type() == metadata.Type {
+			return fmt.Errorf("%s: %s cannot be nested inside itself", path, metadata.Type)
+		}
+	}
 	return nil
 }
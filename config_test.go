@@ -1,14 +1,19 @@
 package leaderreceivercreator
 
 import (
-	"testing"
 	"path/filepath"
+	"testing"
+	"time"
 
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/configtls"
+	"go.opentelemetry.io/collector/confmap"
 	"go.opentelemetry.io/collector/confmap/confmaptest"
-	"github.com/stretchr/testify/require"
+
+	"github.com/skhalash/leaderreceivercreator/internal/leaderelection"
 	"github.com/skhalash/leaderreceivercreator/internal/metadata"
-	"github.com/stretchr/testify/assert"
 )
 
 func TestLoadConfig(t *testing.T) {
@@ -16,6 +21,16 @@ func TestLoadConfig(t *testing.T) {
 
 	cm, err := confmaptest.LoadConf(filepath.Join("testdata", "config.yaml"))
 	require.NoError(t, err)
+	leaderElectorID := component.MustNewIDWithName("leader_elector", "k8s")
+	defaultRenewFailure := RenewFailureConfig{
+		Policy:      RenewFailureStop,
+		GracePeriod: time.Minute,
+	}
+	defaultRestart := RestartConfig{
+		InitialInterval: time.Second,
+		MaxInterval:     30 * time.Second,
+		MaxFailures:     5,
+	}
 
 	tests := []struct {
 		id       component.ID
@@ -24,16 +39,190 @@ func TestLoadConfig(t *testing.T) {
 		{
 			id: component.NewID(metadata.Type),
 			expected: &Config{
-				subreceiverConfig: receiverConfig{
-					id: component.MustNewID("otlp"),
-					config: map[string]any{
-						"protocols": map[string]any{
-							"grpc": nil,
+				LeaderElection:   leaderelection.NewDefaultConfig(),
+				OnRenewFailure:   defaultRenewFailure,
+				RestartOnFailure: defaultRestart,
+				subreceiverConfigs: []receiverConfig{
+					{
+						id: component.MustNewID("otlp"),
+						config: map[string]any{
+							"protocols": map[string]any{
+								"grpc": nil,
+							},
 						},
 					},
 				},
 			},
 		},
+		{
+			id: component.NewIDWithName(metadata.Type, "custom_lease"),
+			expected: &Config{
+				LeaderElection: leaderelection.Config{
+					Backend:         leaderelection.BackendKubernetes,
+					LeaseName:       "my-lease",
+					LeaseNamespace:  "monitoring",
+					LeaseDuration:   30 * time.Second,
+					RenewDeadline:   20 * time.Second,
+					RetryPeriod:     5 * time.Second,
+					Identity:        "collector-0",
+					ReleaseOnCancel: false,
+					K8sAPI: leaderelection.K8sAPIConfig{
+						AuthType:  leaderelection.AuthTypeKubeConfig,
+						Context:   "staging",
+						QPS:       20,
+						Burst:     40,
+						Timeout:   5 * time.Second,
+						UserAgent: "otelcol",
+						RetryOnFailure: leaderelection.K8sAPIRetryConfig{
+							Enabled:         true,
+							InitialInterval: 2 * time.Second,
+							MaxInterval:     time.Minute,
+						},
+					},
+					File: leaderelection.FileConfig{
+						StaleAfter:   15 * time.Second,
+						PollInterval: 2 * time.Second,
+					},
+					Gossip: leaderelection.GossipConfig{
+						BindAddress: "0.0.0.0:7946",
+					},
+					Etcd: leaderelection.EtcdConfig{
+						TLS: configtls.ClientConfig{
+							Insecure: true,
+						},
+						DialTimeout: 5 * time.Second,
+						SessionTTL:  15 * time.Second,
+						KeyPrefix:   "/leader_receiver_creator",
+					},
+					Redis: leaderelection.RedisConfig{
+						TLS: configtls.ClientConfig{
+							Insecure: true,
+						},
+						KeyPrefix: "leader_receiver_creator",
+					},
+				},
+				OnRenewFailure: RenewFailureConfig{
+					Policy:      RenewFailureKeepRunning,
+					GracePeriod: 30 * time.Second,
+				},
+				RestartOnFailure: RestartConfig{
+					InitialInterval: 5 * time.Second,
+					MaxInterval:     time.Minute,
+					MaxFailures:     3,
+				},
+				subreceiverConfigs: []receiverConfig{
+					{
+						id: component.MustNewID("otlp"),
+						config: map[string]any{
+							"protocols": map[string]any{
+								"grpc": nil,
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			id: component.NewIDWithName(metadata.Type, "file"),
+			expected: &Config{
+				LeaderElection: leaderelection.Config{
+					Backend:         leaderelection.BackendFile,
+					LeaseDuration:   15 * time.Second,
+					RenewDeadline:   10 * time.Second,
+					RetryPeriod:     2 * time.Second,
+					ReleaseOnCancel: true,
+					K8sAPI: leaderelection.K8sAPIConfig{
+						AuthType: leaderelection.AuthTypeServiceAccount,
+						QPS:      5,
+						Burst:    10,
+						RetryOnFailure: leaderelection.K8sAPIRetryConfig{
+							InitialInterval: time.Second,
+							MaxInterval:     30 * time.Second,
+						},
+					},
+					File: leaderelection.FileConfig{
+						Path:         "/shared/otelcol/leader.lease",
+						StaleAfter:   30 * time.Second,
+						PollInterval: 5 * time.Second,
+					},
+					Gossip: leaderelection.GossipConfig{
+						BindAddress: "0.0.0.0:7946",
+					},
+					Etcd: leaderelection.EtcdConfig{
+						TLS: configtls.ClientConfig{
+							Insecure: true,
+						},
+						DialTimeout: 5 * time.Second,
+						SessionTTL:  15 * time.Second,
+						KeyPrefix:   "/leader_receiver_creator",
+					},
+					Redis: leaderelection.RedisConfig{
+						TLS: configtls.ClientConfig{
+							Insecure: true,
+						},
+						KeyPrefix: "leader_receiver_creator",
+					},
+				},
+				OnRenewFailure:   defaultRenewFailure,
+				RestartOnFailure: defaultRestart,
+				subreceiverConfigs: []receiverConfig{
+					{
+						id: component.MustNewID("otlp"),
+						config: map[string]any{
+							"protocols": map[string]any{
+								"grpc": nil,
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			id: component.NewIDWithName(metadata.Type, "multiple"),
+			expected: &Config{
+				LeaderElection:   leaderelection.NewDefaultConfig(),
+				OnRenewFailure:   defaultRenewFailure,
+				RestartOnFailure: defaultRestart,
+				subreceiverConfigs: []receiverConfig{
+					{
+						id: component.MustNewID("k8s_cluster"),
+						config: map[string]any{
+							"collection_interval": "10s",
+						},
+					},
+					{
+						id:     component.MustNewID("k8s_events"),
+						config: map[string]any{},
+					},
+					{
+						id: component.MustNewIDWithName("k8sobjects", "events"),
+						config: map[string]any{
+							"objects": []any{
+								map[string]any{
+									"name": "events",
+									"mode": "watch",
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			id: component.NewIDWithName(metadata.Type, "shared"),
+			expected: &Config{
+				LeaderElection:   leaderelection.NewDefaultConfig(),
+				LeaderElector:    &leaderElectorID,
+				OnRenewFailure:   defaultRenewFailure,
+				RestartOnFailure: defaultRestart,
+				subreceiverConfigs: []receiverConfig{
+					{
+						id:     component.MustNewID("k8s_cluster"),
+						config: map[string]any{},
+					},
+				},
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -50,3 +239,102 @@ func TestLoadConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		name           string
+		receivers      map[string]any
+		onRenewFailure map[string]any
+		restart        map[string]any
+		expectedErr    string
+	}{
+		{
+			name: "valid",
+			receivers: map[string]any{
+				"k8s_cluster":       map[string]any{},
+				"k8sobjects/events": map[string]any{},
+			},
+		},
+		{
+			name:        "missing receiver section",
+			expectedErr: "receiver: at least one subreceiver must be configured",
+		},
+		{
+			name:        "empty receiver section",
+			receivers:   map[string]any{},
+			expectedErr: "receiver: at least one subreceiver must be configured",
+		},
+		{
+			name: "ambiguous subreceivers",
+			receivers: map[string]any{
+				"k8s_cluster":  map[string]any{},
+				" k8s_cluster": map[string]any{},
+			},
+			expectedErr: "receiver::k8s_cluster: subreceiver is configured more than once",
+		},
+		{
+			name: "nested leader_receiver_creator",
+			receivers: map[string]any{
+				"leader_receiver_creator/nested": map[string]any{},
+			},
+			expectedErr: "receiver::leader_receiver_creator/nested: leader_receiver_creator cannot be nested inside itself",
+		},
+		{
+			name: "malformed id",
+			receivers: map[string]any{
+				"k8s_cluster/": map[string]any{},
+			},
+			expectedErr: `receiver::k8s_cluster/: failed to parse subreceiver id k8s_cluster/: in "k8s_cluster/" id: the part after / should not be empty`,
+		},
+		{
+			name:           "unknown renew failure policy",
+			receivers:      map[string]any{"k8s_cluster": map[string]any{}},
+			onRenewFailure: map[string]any{"policy": "fail_open"},
+			expectedErr:    `unsupported on_renew_failure::policy "fail_open"`,
+		},
+		{
+			name:           "keep running without grace period",
+			receivers:      map[string]any{"k8s_cluster": map[string]any{}},
+			onRenewFailure: map[string]any{"policy": "keep_running", "grace_period": "0s"},
+			expectedErr:    "on_renew_failure::grace_period (0s) must be positive",
+		},
+		{
+			name:        "restart interval out of order",
+			receivers:   map[string]any{"k8s_cluster": map[string]any{}},
+			restart:     map[string]any{"initial_interval": "1m", "max_interval": "1s"},
+			expectedErr: "restart_on_failure::initial_interval (1m0s) must not be greater than restart_on_failure::max_interval (1s)",
+		},
+		{
+			name:        "negative max failures",
+			receivers:   map[string]any{"k8s_cluster": map[string]any{}},
+			restart:     map[string]any{"max_failures": -1},
+			expectedErr: "restart_on_failure::max_failures (-1) must not be negative",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raw := map[string]any{}
+			if tt.receivers != nil {
+				raw["receiver"] = tt.receivers
+			}
+			if tt.onRenewFailure != nil {
+				raw["on_renew_failure"] = tt.onRenewFailure
+			}
+			if tt.restart != nil {
+				raw["restart_on_failure"] = tt.restart
+			}
+
+			cfg := NewFactory().CreateDefaultConfig()
+			err := component.UnmarshalConfig(confmap.NewFromStringMap(raw), cfg)
+			if err == nil {
+				err = component.ValidateConfig(cfg)
+			}
+			if tt.expectedErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.expectedErr)
+		})
+	}
+}
@@ -9,8 +9,10 @@ import (
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/consumer"
 	"go.opentelemetry.io/collector/receiver"
-	"github.com/skhalash/leaderreceivercreator/internal/sharedcomponent"
+
+	"github.com/skhalash/leaderreceivercreator/internal/leaderelection"
 	"github.com/skhalash/leaderreceivercreator/internal/metadata"
+	"github.com/skhalash/leaderreceivercreator/internal/sharedcomponent"
 )
 
 var receivers = sharedcomponent.NewSharedComponents()
@@ -26,7 +28,18 @@ func NewFactory() receiver.Factory {
 }
 
 func createDefaultConfig() component.Config {
-	return &Config{}
+	return &Config{
+		LeaderElection: leaderelection.NewDefaultConfig(),
+		OnRenewFailure: RenewFailureConfig{
+			Policy:      RenewFailureStop,
+			GracePeriod: defaultRenewFailureGracePeriod,
+		},
+		RestartOnFailure: RestartConfig{
+			InitialInterval: defaultRestartInitialInterval,
+			MaxInterval:     defaultRestartMaxInterval,
+			MaxFailures:     defaultRestartMaxFailures,
+		},
+	}
 }
 
 func createLogsReceiver(
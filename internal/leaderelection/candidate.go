@@ -0,0 +1,319 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package leaderelection // import "github.com/skhalash/leaderreceivercreator/internal/leaderelection"
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// Candidate campaigns for the leadership until it is shut down and dispatches the leadership changes to its
+// subscribers, so that several leader-gated components can share one election.
+type Candidate struct {
+	elector Elector
+	backend string
+	logger  *zap.Logger
+	tracer  trace.Tracer
+	// stepDownPeriod is how long the candidate stays out of the election after stepping down.
+	stepDownPeriod time.Duration
+
+	cancel context.CancelFunc
+	// done is closed once the election loop has exited.
+	done chan struct{}
+
+	// stepDownLock guards the cancellation of the current campaign, so that StepDown can end it.
+	stepDownLock   sync.Mutex
+	cancelCampaign context.CancelFunc
+	steppedDown    bool
+
+	// leadershipLock serializes the leadership changes with the subscriptions, so that a subscriber
+	// never sees them out of order.
+	leadershipLock sync.Mutex
+	leaderCtx      context.Context
+
+	subscribersLock sync.RWMutex
+	// subscribers are kept in the order of subscription, so that they are notified in a stable order.
+	subscribers    []subscriber
+	nextSubscriber int
+
+	// campaignSpan traces the current campaign until the leadership is acquired or the campaign ends.
+	campaignLock sync.Mutex
+	campaignSpan trace.Span
+}
+
+type subscriber struct {
+	id        int
+	callbacks Callbacks
+}
+
+// NewCandidate creates a Candidate for the backend selected in the configuration. The lease name defaults to
+// a name derived from the ID of the component the candidate campaigns for, and the lease namespace to the
+// namespace the collector runs in.
+func NewCandidate(cfg Config, id component.ID, set component.TelemetrySettings, tracer trace.Tracer) (*Candidate, error) {
+	if cfg.LeaseName == "" {
+		cfg.LeaseName = leaseNameFromID(id)
+	}
+	cfg.LeaseNamespace = leaseNamespace(cfg.LeaseNamespace, inClusterNamespacePath, set.Logger)
+	c := &Candidate{
+		backend:        cfg.Backend,
+		logger:         set.Logger,
+		tracer:         tracer,
+		stepDownPeriod: cfg.LeaseDuration,
+	}
+	elector, err := New(cfg, set, Callbacks{
+		OnStartedLeading: c.startedLeading,
+		OnStoppedLeading: c.stoppedLeading,
+		OnNewLeader:      c.newLeader,
+		OnRenew:          c.renewed,
+		OnBackendError:   c.backendError,
+	})
+	if err != nil {
+		return nil, err
+	}
+	c.elector = elector
+	set.Logger.Info("Created leader election candidate", zap.String("backend", cfg.Backend),
+		zap.String("identity", elector.Identity()), zap.String("lease", cfg.LeaseNamespace+"/"+cfg.LeaseName))
+	return c, nil
+}
+
+// Start starts the election loop. The loop outlives the call, so it is not bound to any context.
+func (c *Candidate) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	c.cancel = cancel
+	c.done = make(chan struct{})
+	go c.run(ctx)
+}
+
+// Shutdown stops the election loop and waits for it to exit. The leader-gated work of the subscribers must
+// be stopped before, because the lease may be released once the loop exits.
+func (c *Candidate) Shutdown(ctx context.Context) error {
+	if c.cancel == nil {
+		return nil
+	}
+	c.cancel()
+
+	select {
+	case <-c.done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("failed to wait for the leader election loop to exit: %w", ctx.Err())
+	}
+}
+
+// Subscribe registers callbacks that are invoked when the leadership changes and returns a function that
+// removes them again. OnStartedLeading is invoked right away if the candidate is the leader, and
+// OnStoppedLeading is only invoked after OnStartedLeading. The context passed to OnStartedLeading holds the
+// span of the campaign that acquired the leadership and is cancelled when the leadership is lost.
+func (c *Candidate) Subscribe(callbacks Callbacks) (unsubscribe func()) {
+	c.leadershipLock.Lock()
+	defer c.leadershipLock.Unlock()
+
+	c.subscribersLock.Lock()
+	id := c.nextSubscriber
+	c.nextSubscriber++
+	c.subscribers = append(c.subscribers, subscriber{id: id, callbacks: callbacks})
+	c.subscribersLock.Unlock()
+
+	if c.leaderCtx != nil && callbacks.OnStartedLeading != nil {
+		callbacks.OnStartedLeading(c.leaderCtx)
+	}
+
+	return func() {
+		c.leadershipLock.Lock()
+		defer c.leadershipLock.Unlock()
+		c.subscribersLock.Lock()
+		defer c.subscribersLock.Unlock()
+		for i, s := range c.subscribers {
+			if s.id == id {
+				c.subscribers = append(c.subscribers[:i:i], c.subscribers[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// IsLeader returns true if the candidate is currently the leader.
+func (c *Candidate) IsLeader() bool {
+	return c.elector.IsLeader()
+}
+
+// GetLeader returns the identity of the last observed leader.
+func (c *Candidate) GetLeader() string {
+	return c.elector.GetLeader()
+}
+
+// Identity returns the identity of this candidate.
+func (c *Candidate) Identity() string {
+	return c.elector.Identity()
+}
+
+// StepDown gives up the leadership and keeps the candidate out of the election for the lease duration, so
+// that another candidate can take over. It does nothing if the candidate is not the leader. The subscribers
+// are notified of the lost leadership as usual.
+func (c *Candidate) StepDown() {
+	c.stepDownLock.Lock()
+	defer c.stepDownLock.Unlock()
+
+	if c.cancelCampaign == nil || c.steppedDown || !c.elector.IsLeader() {
+		return
+	}
+	c.logger.Info("Stepping down from the leadership", zap.Duration("period", c.stepDownPeriod))
+	c.steppedDown = true
+	c.cancelCampaign()
+}
+
+// run campaigns for leadership until ctx is cancelled. The Run method of the elector returns as soon as the
+// leadership is lost, so it is restarted to let this candidate compete again.
+func (c *Candidate) run(ctx context.Context) {
+	defer close(c.done)
+
+	for {
+		c.startCampaign()
+		steppedDown := c.campaign(ctx)
+		c.endCampaign(false)
+		if ctx.Err() != nil {
+			return
+		}
+		if !steppedDown {
+			c.logger.Info("Leader election loop exited, campaigning again...")
+			continue
+		}
+
+		timer := time.NewTimer(c.stepDownPeriod)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		c.logger.Info("Step down period elapsed, campaigning again...")
+	}
+}
+
+// campaign runs the elector until ctx is cancelled, the leadership is lost or the candidate steps down, and
+// returns true in the latter case.
+func (c *Candidate) campaign(ctx context.Context) bool {
+	campaignCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	c.stepDownLock.Lock()
+	c.cancelCampaign = cancel
+	c.stepDownLock.Unlock()
+
+	c.elector.Run(campaignCtx)
+
+	c.stepDownLock.Lock()
+	defer c.stepDownLock.Unlock()
+	c.cancelCampaign = nil
+	steppedDown := c.steppedDown
+	c.steppedDown = false
+	return steppedDown
+}
+
+func (c *Candidate) startedLeading(ctx context.Context) {
+	c.leadershipLock.Lock()
+	defer c.leadershipLock.Unlock()
+
+	// The elector starts this callback in a goroutine, so the leadership might already be lost.
+	if ctx.Err() != nil {
+		c.endCampaign(false)
+		return
+	}
+	// The leader-gated work is traced along with the campaign that acquired the leadership.
+	if span := c.endCampaign(true); span != nil {
+		ctx = trace.ContextWithSpan(ctx, span)
+	}
+	c.leaderCtx = ctx
+	for _, callbacks := range c.subscribersSnapshot() {
+		if callbacks.OnStartedLeading != nil {
+			callbacks.OnStartedLeading(ctx)
+		}
+	}
+}
+
+func (c *Candidate) stoppedLeading() {
+	c.leadershipLock.Lock()
+	defer c.leadershipLock.Unlock()
+
+	if c.leaderCtx == nil {
+		return
+	}
+	c.leaderCtx = nil
+	for _, callbacks := range c.subscribersSnapshot() {
+		if callbacks.OnStoppedLeading != nil {
+			callbacks.OnStoppedLeading()
+		}
+	}
+}
+
+// newLeader, renewed and backendError are not serialized with the leadership changes, because they must not block.
+func (c *Candidate) newLeader(identity string) {
+	for _, callbacks := range c.subscribersSnapshot() {
+		callbacks.newLeader(identity)
+	}
+}
+
+func (c *Candidate) renewed(latency time.Duration, err error) {
+	for _, callbacks := range c.subscribersSnapshot() {
+		callbacks.renewed(latency, err)
+	}
+}
+
+func (c *Candidate) backendError(err error) {
+	if err != nil {
+		c.logger.Warn("Failed to reach the leader election backend, retrying...", zap.Error(err))
+	} else {
+		c.logger.Info("Leader election backend reached")
+	}
+	for _, callbacks := range c.subscribersSnapshot() {
+		callbacks.backendError(err)
+	}
+}
+
+func (c *Candidate) subscribersSnapshot() []Callbacks {
+	c.subscribersLock.RLock()
+	defer c.subscribersLock.RUnlock()
+
+	snapshot := make([]Callbacks, 0, len(c.subscribers))
+	for _, s := range c.subscribers {
+		snapshot = append(snapshot, s.callbacks)
+	}
+	return snapshot
+}
+
+// startCampaign starts the span of a campaign for the leadership.
+func (c *Candidate) startCampaign() {
+	c.campaignLock.Lock()
+	defer c.campaignLock.Unlock()
+
+	_, c.campaignSpan = c.tracer.Start(context.Background(), "campaign", trace.WithAttributes(
+		attribute.String("leader_election.backend", c.backend),
+		attribute.String("leader_election.identity", c.elector.Identity()),
+	))
+}
+
+// endCampaign ends the span of the current campaign, if any, and returns it.
+func (c *Candidate) endCampaign(elected bool) trace.Span {
+	c.campaignLock.Lock()
+	defer c.campaignLock.Unlock()
+
+	if c.campaignSpan == nil {
+		return nil
+	}
+	span := c.campaignSpan
+	c.campaignSpan = nil
+	span.SetAttributes(attribute.Bool("leader_election.elected", elected))
+	if elected {
+		span.AddEvent("Leadership acquired")
+	}
+	span.End()
+	return span
+}
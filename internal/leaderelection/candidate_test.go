@@ -0,0 +1,125 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package leaderelection
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+func newTestCandidate(t *testing.T, identity string) *Candidate {
+	cfg := NewDefaultConfig()
+	cfg.Backend = BackendMemory
+	cfg.LeaseName = t.Name()
+	cfg.Identity = identity
+
+	candidate, err := NewCandidate(cfg, component.MustNewID("test"), componenttest.NewNopTelemetrySettings(), noop.NewTracerProvider().Tracer(""))
+	require.NoError(t, err)
+	return candidate
+}
+
+func TestCandidateSubscribers(t *testing.T) {
+	first := newTestCandidate(t, "first")
+	second := newTestCandidate(t, "second")
+
+	var firstLeading, secondLeading atomic.Bool
+	subscribe := func(c *Candidate, leading *atomic.Bool) func() {
+		return c.Subscribe(Callbacks{
+			OnStartedLeading: func(context.Context) { leading.Store(true) },
+			OnStoppedLeading: func() { leading.Store(false) },
+		})
+	}
+	subscribe(first, &firstLeading)
+	subscribe(second, &secondLeading)
+
+	first.Start()
+	require.Eventually(t, firstLeading.Load, 5*time.Second, 10*time.Millisecond)
+	second.Start()
+	defer func() {
+		require.NoError(t, second.Shutdown(context.Background()))
+	}()
+
+	// A late subscriber is told about the leadership right away.
+	var lateLeading atomic.Bool
+	unsubscribe := subscribe(first, &lateLeading)
+	assert.True(t, lateLeading.Load())
+	unsubscribe()
+
+	require.NoError(t, first.Shutdown(context.Background()))
+	assert.False(t, firstLeading.Load())
+	// Unsubscribed callbacks are not invoked anymore.
+	assert.True(t, lateLeading.Load())
+	assert.Eventually(t, secondLeading.Load, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, "second", second.GetLeader())
+}
+
+func TestCandidateDefaultLeaseName(t *testing.T) {
+	cfg := NewDefaultConfig()
+	cfg.Backend = BackendMemory
+
+	// Both candidates lead, because the lease names derived from their IDs differ.
+	for _, name := range []string{"a", "b"} {
+		candidate, err := NewCandidate(cfg, component.MustNewIDWithName("default_lease_name", name),
+			componenttest.NewNopTelemetrySettings(), noop.NewTracerProvider().Tracer(""))
+		require.NoError(t, err)
+		candidate.Start()
+		assert.Eventually(t, candidate.IsLeader, 5*time.Second, 10*time.Millisecond)
+		defer func() {
+			require.NoError(t, candidate.Shutdown(context.Background()))
+		}()
+	}
+}
+
+func TestCandidateStepDown(t *testing.T) {
+	first := newTestCandidate(t, "first")
+	first.stepDownPeriod = 200 * time.Millisecond
+	second := newTestCandidate(t, "second")
+
+	var firstLeading atomic.Bool
+	first.Subscribe(Callbacks{
+		OnStartedLeading: func(context.Context) { firstLeading.Store(true) },
+		OnStoppedLeading: func() { firstLeading.Store(false) },
+	})
+
+	first.Start()
+	defer func() {
+		require.NoError(t, first.Shutdown(context.Background()))
+	}()
+	require.Eventually(t, firstLeading.Load, 5*time.Second, 10*time.Millisecond)
+	second.Start()
+
+	// Stepping down as a follower does nothing.
+	second.StepDown()
+	first.StepDown()
+	assert.Eventually(t, second.IsLeader, 5*time.Second, 10*time.Millisecond)
+	assert.False(t, firstLeading.Load())
+
+	// The candidate campaigns again once the step down period elapsed.
+	require.NoError(t, second.Shutdown(context.Background()))
+	assert.Eventually(t, firstLeading.Load, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestCandidateShutdownWithoutStart(t *testing.T) {
+	candidate := newTestCandidate(t, "")
+	require.NoError(t, candidate.Shutdown(context.Background()))
+}
+
+func TestCandidateShutdownHonoursContext(t *testing.T) {
+	candidate := newTestCandidate(t, "")
+	// Simulate an election loop that never exits.
+	candidate.cancel = func() {}
+	candidate.done = make(chan struct{})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, candidate.Shutdown(ctx), context.DeadlineExceeded)
+}
@@ -0,0 +1,178 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package leaderelection // import "github.com/skhalash/leaderreceivercreator/internal/leaderelection"
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/configtls"
+)
+
+const (
+	// BackendKubernetes elects the leader using a Kubernetes Lease.
+	BackendKubernetes = "kubernetes"
+	// BackendMemory elects the leader among the candidates running in the same process.
+	BackendMemory = "memory"
+	// BackendFile elects the leader using a lock file shared by the candidates.
+	BackendFile = "file"
+	// BackendRaft elects the leader using an embedded raft cluster formed by the candidates.
+	BackendRaft = "raft"
+	// BackendGossip elects the member with the lowest identity among the members of a gossip cluster.
+	BackendGossip = "gossip"
+	// BackendEtcd elects the leader using the election primitive of etcd.
+	BackendEtcd = "etcd"
+	// BackendRedis elects the leader using a lock key in redis.
+	BackendRedis = "redis"
+
+	// maxLeaseNameLength is the maximum length of a DNS-1123 subdomain.
+	maxLeaseNameLength   = 253
+	defaultLeaseDuration = 15 * time.Second
+	defaultRenewDeadline = 10 * time.Second
+	defaultRetryPeriod   = 2 * time.Second
+
+	defaultFileStaleAfter   = 15 * time.Second
+	defaultFilePollInterval = 2 * time.Second
+)
+
+// Config defines the settings of the leader election.
+type Config struct {
+	// Backend is the leader election implementation to use.
+	Backend string `mapstructure:"backend"`
+	// LeaseName is the name of the Lease object used as a lock. Defaults to a name derived from the ID of the
+	// component, so that every instance of a leader-gated component has an election of its own.
+	LeaseName string `mapstructure:"lease_name"`
+	// LeaseNamespace is the namespace of the Lease object used as a lock. Defaults to the namespace the
+	// collector runs in.
+	LeaseNamespace string `mapstructure:"lease_namespace"`
+	// LeaseDuration is the duration that non-leader candidates will wait to force acquire leadership.
+	LeaseDuration time.Duration `mapstructure:"lease_duration"`
+	// RenewDeadline is the duration that the acting leader will retry refreshing leadership before giving up.
+	RenewDeadline time.Duration `mapstructure:"renew_deadline"`
+	// RetryPeriod is the duration the candidates should wait between tries of actions.
+	RetryPeriod time.Duration `mapstructure:"retry_period"`
+	// Identity is the unique id of this candidate: one of the hostname, hostname_random and pod_name
+	// strategies, or a template in which the environment variables are expanded. Defaults to the hostname.
+	Identity string `mapstructure:"identity"`
+	// ReleaseOnCancel releases the Lease on shutdown, so that another candidate can take over
+	// without waiting for the Lease to expire.
+	ReleaseOnCancel bool `mapstructure:"release_on_cancel"`
+
+	// K8sAPI configures the Kubernetes API client of the kubernetes backend.
+	K8sAPI K8sAPIConfig `mapstructure:"k8s_api"`
+	// File configures the file backend.
+	File FileConfig `mapstructure:"file"`
+	// Raft configures the raft backend.
+	Raft RaftConfig `mapstructure:"raft"`
+	// Gossip configures the gossip backend.
+	Gossip GossipConfig `mapstructure:"gossip"`
+	// Etcd configures the etcd backend.
+	Etcd EtcdConfig `mapstructure:"etcd"`
+	// Redis configures the redis backend.
+	Redis RedisConfig `mapstructure:"redis"`
+}
+
+// NewDefaultConfig returns the default leader election configuration.
+func NewDefaultConfig() Config {
+	return Config{
+		Backend:         BackendKubernetes,
+		LeaseDuration:   defaultLeaseDuration,
+		RenewDeadline:   defaultRenewDeadline,
+		RetryPeriod:     defaultRetryPeriod,
+		ReleaseOnCancel: true,
+		K8sAPI: K8sAPIConfig{
+			AuthType: AuthTypeServiceAccount,
+			QPS:      defaultK8sAPIQPS,
+			Burst:    defaultK8sAPIBurst,
+			RetryOnFailure: K8sAPIRetryConfig{
+				InitialInterval: defaultK8sAPIRetryInitialInterval,
+				MaxInterval:     defaultK8sAPIRetryMaxInterval,
+			},
+		},
+		File: FileConfig{
+			StaleAfter:   defaultFileStaleAfter,
+			PollInterval: defaultFilePollInterval,
+		},
+		Gossip: GossipConfig{
+			BindAddress: defaultGossipBindAddress,
+		},
+		Etcd: EtcdConfig{
+			TLS: configtls.ClientConfig{
+				Insecure: true,
+			},
+			DialTimeout: defaultEtcdDialTimeout,
+			SessionTTL:  defaultEtcdSessionTTL,
+			KeyPrefix:   defaultEtcdKeyPrefix,
+		},
+		Redis: RedisConfig{
+			TLS: configtls.ClientConfig{
+				Insecure: true,
+			},
+			KeyPrefix: defaultRedisKeyPrefix,
+		},
+	}
+}
+
+// Validate checks if the leader election configuration is valid.
+func (cfg *Config) Validate() error {
+	if _, ok := backends[cfg.Backend]; !ok {
+		return fmt.Errorf("unsupported backend %q", cfg.Backend)
+	}
+	if cfg.LeaseDuration <= 0 || cfg.RenewDeadline <= 0 || cfg.RetryPeriod <= 0 {
+		return errors.New("lease_duration, renew_deadline and retry_period must be positive")
+	}
+	if cfg.RenewDeadline >= cfg.LeaseDuration {
+		return fmt.Errorf("renew_deadline (%v) must be less than lease_duration (%v)", cfg.RenewDeadline, cfg.LeaseDuration)
+	}
+	if cfg.RetryPeriod >= cfg.RenewDeadline {
+		return fmt.Errorf("retry_period (%v) must be less than renew_deadline (%v)", cfg.RetryPeriod, cfg.RenewDeadline)
+	}
+	switch cfg.Backend {
+	case BackendKubernetes:
+		return cfg.K8sAPI.validate()
+	case BackendFile:
+		return cfg.File.validate()
+	case BackendRaft:
+		return cfg.Raft.validate()
+	case BackendGossip:
+		return cfg.Gossip.validate()
+	case BackendEtcd:
+		return cfg.Etcd.validate()
+	case BackendRedis:
+		return cfg.Redis.validate()
+	}
+	return nil
+}
+
+// leaseNameFromID returns the ID of the component sanitized to a DNS-1123 subdomain, e.g.
+// leader-receiver-creator-a-9e4489f7 for leader_receiver_creator/a. Sanitizing is lossy, e.g. a_b, a-b and A-B
+// map to the same name, so a short hash of the ID is appended whenever the sanitized name differs from it.
+func leaseNameFromID(id component.ID) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '-', r == '.':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r - 'A' + 'a'
+		default:
+			return '-'
+		}
+	}, id.String())
+	if name == id.String() && len(name) <= maxLeaseNameLength {
+		return name
+	}
+
+	hash := fnv.New32a()
+	_, _ = hash.Write([]byte(id.String()))
+	suffix := fmt.Sprintf("-%08x", hash.Sum32())
+	if len(name) > maxLeaseNameLength-len(suffix) {
+		name = name[:maxLeaseNameLength-len(suffix)]
+	}
+	// A DNS-1123 subdomain must start and end with an alphanumeric character.
+	return strings.Trim(name, "-.") + suffix
+}
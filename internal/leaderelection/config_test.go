@@ -0,0 +1,134 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package leaderelection
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/collector/component"
+)
+
+func TestConfigValidate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		modify      func(cfg *Config)
+		expectedErr string
+	}{
+		{
+			name:   "default",
+			modify: func(*Config) {},
+		},
+		{
+			name:        "unknown backend",
+			modify:      func(cfg *Config) { cfg.Backend = "zookeeper" },
+			expectedErr: `unsupported backend "zookeeper"`,
+		},
+		{
+			name:        "unknown k8s api auth type",
+			modify:      func(cfg *Config) { cfg.K8sAPI.AuthType = "tls" },
+			expectedErr: `unsupported k8s_api::auth_type "tls"`,
+		},
+		{
+			name:        "kubeconfig context without kubeconfig auth type",
+			modify:      func(cfg *Config) { cfg.K8sAPI.Context = "staging" },
+			expectedErr: "k8s_api::kubeconfig and k8s_api::context require the kubeConfig auth_type",
+		},
+		{
+			name:        "zero k8s api burst",
+			modify:      func(cfg *Config) { cfg.K8sAPI.Burst = 0 },
+			expectedErr: "k8s_api::qps and k8s_api::burst must be positive",
+		},
+		{
+			name: "k8s api retry with initial interval greater than max interval",
+			modify: func(cfg *Config) {
+				cfg.K8sAPI.RetryOnFailure.Enabled = true
+				cfg.K8sAPI.RetryOnFailure.InitialInterval = time.Minute
+			},
+			expectedErr: "k8s_api::retry_on_failure::initial_interval (1m0s) must not be greater than k8s_api::retry_on_failure::max_interval (30s)",
+		},
+		{
+			name: "k8s api settings ignored by other backends",
+			modify: func(cfg *Config) {
+				cfg.Backend = BackendMemory
+				cfg.K8sAPI.AuthType = "tls"
+			},
+		},
+		{
+			name:        "zero retry period",
+			modify:      func(cfg *Config) { cfg.RetryPeriod = 0 },
+			expectedErr: "lease_duration, renew_deadline and retry_period must be positive",
+		},
+		{
+			name:        "renew deadline not less than lease duration",
+			modify:      func(cfg *Config) { cfg.RenewDeadline = cfg.LeaseDuration },
+			expectedErr: "renew_deadline (15s) must be less than lease_duration (15s)",
+		},
+		{
+			name:        "retry period not less than renew deadline",
+			modify:      func(cfg *Config) { cfg.RetryPeriod = cfg.RenewDeadline },
+			expectedErr: "retry_period (10s) must be less than renew_deadline (10s)",
+		},
+		{
+			name: "file backend",
+			modify: func(cfg *Config) {
+				cfg.Backend = BackendFile
+				cfg.File.Path = "/var/lib/otelcol/leader.lease"
+			},
+		},
+		{
+			name:        "file backend without path",
+			modify:      func(cfg *Config) { cfg.Backend = BackendFile },
+			expectedErr: "file::path must not be empty",
+		},
+		{
+			name: "file backend with too long poll interval",
+			modify: func(cfg *Config) {
+				cfg.Backend = BackendFile
+				cfg.File.Path = "/var/lib/otelcol/leader.lease"
+				cfg.File.PollInterval = 10 * time.Second
+			},
+			expectedErr: "file::poll_interval (10s) must not be greater than half of file::stale_after (15s)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := NewDefaultConfig()
+			tt.modify(&cfg)
+
+			err := cfg.Validate()
+			if tt.expectedErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.expectedErr)
+		})
+	}
+}
+
+func TestLeaseNameFromID(t *testing.T) {
+	tests := []struct {
+		id       component.ID
+		expected string
+	}{
+		{id: component.MustNewID("leader_receiver_creator"), expected: "leader-receiver-creator-9f080acb"},
+		{id: component.MustNewIDWithName("leader_receiver_creator", "a"), expected: "leader-receiver-creator-a-9e4489f7"},
+		{id: component.MustNewIDWithName("leader_filter", "K8s.Cluster_"), expected: "leader-filter-k8s.cluster-620a44d5"},
+		{id: component.MustNewIDWithName("leader_exporter", strings.Repeat("x", 300)), expected: "leader-exporter-" + strings.Repeat("x", 228) + "-01a870c9"},
+		{id: component.MustNewIDWithName("leader_filter", "a_b"), expected: "leader-filter-a-b-fab79ef6"},
+		{id: component.MustNewIDWithName("leader_filter", "a-b"), expected: "leader-filter-a-b-e2e305cc"},
+		{id: component.MustNewIDWithName("leader_filter", "A-B"), expected: "leader-filter-a-b-26ac328c"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			assert.Equal(t, tt.expected, leaseNameFromID(tt.id))
+		})
+	}
+}
@@ -0,0 +1,147 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package leaderelection implements the backends used to elect a single leader among the collector replicas.
+package leaderelection // import "github.com/skhalash/leaderreceivercreator/internal/leaderelection"
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"k8s.io/apimachinery/pkg/util/rand"
+)
+
+const (
+	// IdentityHostname uses the hostname as the identity of the candidate.
+	IdentityHostname = "hostname"
+	// IdentityHostnameRandom appends a random suffix to the hostname, so that the candidates sharing a
+	// hostname, e.g. in the same pod or on a node with host networking, have distinct identities.
+	IdentityHostnameRandom = "hostname_random"
+	// IdentityPodName uses the pod name exposed by the downward API in the POD_NAME environment variable.
+	IdentityPodName = "pod_name"
+
+	podNameEnv           = "POD_NAME"
+	identitySuffixLength = 5
+)
+
+// Callbacks are invoked by an Elector when the leadership changes.
+type Callbacks struct {
+	// OnStartedLeading is called in a separate goroutine when the candidate becomes the leader.
+	// The context is cancelled when the leadership is lost.
+	OnStartedLeading func(ctx context.Context)
+	// OnStoppedLeading is called when Run returns, regardless of whether the candidate was the leader.
+	OnStoppedLeading func()
+	// OnNewLeader is called when the observed leader changes, with an empty identity if the leadership
+	// is observed to be vacant. It is optional and must not block.
+	OnNewLeader func(identity string)
+	// OnRenew is called after the leader tried to renew its lease, with the latency of the attempt.
+	// It is optional, must not block and is only called by the backends that renew a lease.
+	OnRenew func(latency time.Duration, err error)
+	// OnBackendError is called with the error when the backend cannot be reached and with nil once it is
+	// reached again. It is optional, must not block and is only called by the kubernetes backend.
+	OnBackendError func(err error)
+}
+
+func (c Callbacks) newLeader(identity string) {
+	if c.OnNewLeader != nil {
+		c.OnNewLeader(identity)
+	}
+}
+
+func (c Callbacks) renewed(latency time.Duration, err error) {
+	if c.OnRenew != nil {
+		c.OnRenew(latency, err)
+	}
+}
+
+func (c Callbacks) backendError(err error) {
+	if c.OnBackendError != nil {
+		c.OnBackendError(err)
+	}
+}
+
+// leaderObserver reports the changes of the observed leader to Callbacks.OnNewLeader.
+type leaderObserver struct {
+	lock     sync.Mutex
+	leader   string
+	observed bool
+}
+
+func (o *leaderObserver) observe(callbacks Callbacks, leader string) {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	if o.observed && o.leader == leader {
+		return
+	}
+	o.leader = leader
+	o.observed = true
+	callbacks.newLeader(leader)
+}
+
+// Elector campaigns for leadership on behalf of a candidate.
+type Elector interface {
+	// Run campaigns for leadership and blocks until ctx is cancelled or the leadership is lost.
+	// It can be called again after it returns to campaign anew.
+	Run(ctx context.Context)
+	// IsLeader returns true if the candidate is currently the leader.
+	IsLeader() bool
+	// GetLeader returns the identity of the last observed leader.
+	GetLeader() string
+	// Identity returns the identity of this candidate.
+	Identity() string
+}
+
+// factory creates an Elector for a particular backend.
+type factory func(cfg Config, set component.TelemetrySettings, callbacks Callbacks) (Elector, error)
+
+var backends = map[string]factory{
+	BackendKubernetes: newKubernetesElector,
+	BackendMemory:     newMemoryElector,
+	BackendFile:       newFileElector,
+	BackendRaft:       newRaftElector,
+	BackendGossip:     newGossipElector,
+	BackendEtcd:       newEtcdElector,
+	BackendRedis:      newRedisElector,
+}
+
+// New creates an Elector for the backend selected in the configuration.
+func New(cfg Config, set component.TelemetrySettings, callbacks Callbacks) (Elector, error) {
+	create, ok := backends[cfg.Backend]
+	if !ok {
+		return nil, fmt.Errorf("unsupported backend %q", cfg.Backend)
+	}
+	return create(cfg, set, callbacks)
+}
+
+// candidateIdentity resolves the configured identity strategy. Values other than the strategies are templates
+// in which the environment variables are expanded.
+func candidateIdentity(identity string) (string, error) {
+	switch identity {
+	case "", IdentityHostname:
+		return os.Hostname()
+	case IdentityHostnameRandom:
+		hostname, err := os.Hostname()
+		if err != nil {
+			return "", err
+		}
+		return hostname + "-" + rand.String(identitySuffixLength), nil
+	case IdentityPodName:
+		podName := os.Getenv(podNameEnv)
+		if podName == "" {
+			return "", fmt.Errorf("identity %s requires the %s environment variable", IdentityPodName, podNameEnv)
+		}
+		return podName, nil
+	}
+
+	expanded := os.ExpandEnv(identity)
+	if expanded == "" {
+		return "", errors.New("identity must not expand to an empty string")
+	}
+	return expanded, nil
+}
@@ -0,0 +1,76 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package leaderelection
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// runTestElector campaigns with the elector until the returned function is called or the test ends.
+// Like the callers of the elector, it campaigns again when the leadership is lost.
+func runTestElector(t *testing.T, elector Elector) (stop func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for ctx.Err() == nil {
+			elector.Run(ctx)
+		}
+	}()
+
+	stop = func() {
+		cancel()
+		<-done
+	}
+	t.Cleanup(stop)
+	return stop
+}
+
+func TestCandidateIdentity(t *testing.T) {
+	hostname, err := os.Hostname()
+	require.NoError(t, err)
+	t.Setenv("POD_NAME", "collector-0")
+	t.Setenv("NODE_NAME", "node-1")
+
+	tests := []struct {
+		identity    string
+		expected    string
+		expectedErr string
+	}{
+		{identity: "", expected: hostname},
+		{identity: IdentityHostname, expected: hostname},
+		{identity: IdentityPodName, expected: "collector-0"},
+		{identity: "${NODE_NAME}-gateway", expected: "node-1-gateway"},
+		{identity: "collector", expected: "collector"},
+		{identity: "$UNSET_IDENTITY", expectedErr: "identity must not expand to an empty string"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.identity, func(t *testing.T) {
+			identity, err := candidateIdentity(tt.identity)
+			if tt.expectedErr != "" {
+				assert.EqualError(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, identity)
+		})
+	}
+
+	first, err := candidateIdentity(IdentityHostnameRandom)
+	require.NoError(t, err)
+	second, err := candidateIdentity(IdentityHostnameRandom)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(first, hostname+"-"))
+	assert.NotEqual(t, first, second)
+
+	t.Setenv("POD_NAME", "")
+	_, err = candidateIdentity(IdentityPodName)
+	assert.EqualError(t, err, "identity pod_name requires the POD_NAME environment variable")
+}
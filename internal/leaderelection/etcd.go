@@ -0,0 +1,259 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package leaderelection // import "github.com/skhalash/leaderreceivercreator/internal/leaderelection"
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"sync"
+	"time"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.etcd.io/etcd/client/v3/concurrency"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/configopaque"
+	"go.opentelemetry.io/collector/config/configtls"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultEtcdSessionTTL  = 15 * time.Second
+	defaultEtcdDialTimeout = 5 * time.Second
+	defaultEtcdKeyPrefix   = "/leader_receiver_creator"
+	etcdResignTimeout      = 5 * time.Second
+)
+
+// EtcdConfig defines the settings of the etcd backend.
+type EtcdConfig struct {
+	// Endpoints are the URLs of the etcd members.
+	Endpoints []string `mapstructure:"endpoints"`
+	// TLS configures the connection to etcd. TLS is disabled by default.
+	TLS configtls.ClientConfig `mapstructure:"tls"`
+	// Username is the etcd user name used for authentication.
+	Username string `mapstructure:"username"`
+	// Password is the etcd password used for authentication.
+	Password configopaque.String `mapstructure:"password"`
+	// DialTimeout is the timeout for establishing a connection to etcd.
+	DialTimeout time.Duration `mapstructure:"dial_timeout"`
+	// SessionTTL is the TTL of the etcd lease backing the session of the candidate. The leadership is lost
+	// if the lease cannot be kept alive for this duration.
+	SessionTTL time.Duration `mapstructure:"session_ttl"`
+	// KeyPrefix is the prefix of the election keys, the lease name is appended to it.
+	KeyPrefix string `mapstructure:"key_prefix"`
+}
+
+func (cfg *EtcdConfig) validate() error {
+	if len(cfg.Endpoints) == 0 {
+		return errors.New("etcd::endpoints must not be empty")
+	}
+	if cfg.SessionTTL < time.Second {
+		return fmt.Errorf("etcd::session_ttl (%v) must be at least 1s", cfg.SessionTTL)
+	}
+	if cfg.DialTimeout <= 0 {
+		return errors.New("etcd::dial_timeout must be positive")
+	}
+	if cfg.KeyPrefix == "" {
+		return errors.New("etcd::key_prefix must not be empty")
+	}
+	return nil
+}
+
+// etcdElector elects the leader using the election primitive of etcd. The candidates campaign by creating keys
+// attached to their session lease under a shared prefix, and the candidate with the oldest key is the leader.
+type etcdElector struct {
+	cfg             EtcdConfig
+	prefix          string
+	identity        string
+	retryPeriod     time.Duration
+	releaseOnCancel bool
+	callbacks       Callbacks
+	logger          *zap.Logger
+	observer        leaderObserver
+
+	lock     sync.Mutex
+	client   *clientv3.Client
+	leader   string
+	isLeader bool
+}
+
+func newEtcdElector(cfg Config, set component.TelemetrySettings, callbacks Callbacks) (Elector, error) {
+	identity, err := candidateIdentity(cfg.Identity)
+	if err != nil {
+		return nil, err
+	}
+	return &etcdElector{
+		cfg:             cfg.Etcd,
+		prefix:          path.Join(cfg.Etcd.KeyPrefix, cfg.LeaseNamespace, cfg.LeaseName),
+		identity:        identity,
+		retryPeriod:     cfg.RetryPeriod,
+		releaseOnCancel: cfg.ReleaseOnCancel,
+		callbacks:       callbacks,
+		logger:          set.Logger,
+	}, nil
+}
+
+func (e *etcdElector) Run(ctx context.Context) {
+	defer e.callbacks.OnStoppedLeading()
+
+	if err := e.campaign(ctx); err != nil && ctx.Err() == nil {
+		e.logger.Error("Failed to campaign for leadership in etcd", zap.Error(err))
+		// Wait before returning to not campaign in a tight loop.
+		select {
+		case <-ctx.Done():
+		case <-time.After(e.retryPeriod):
+		}
+	}
+
+	if ctx.Err() != nil {
+		e.closeClient()
+	}
+}
+
+// campaign blocks until the candidate is elected and then until ctx is cancelled or the session expires.
+func (e *etcdElector) campaign(ctx context.Context) error {
+	client, err := e.getClient()
+	if err != nil {
+		return err
+	}
+
+	session, err := concurrency.NewSession(client,
+		concurrency.WithTTL(int(e.cfg.SessionTTL.Seconds())),
+		concurrency.WithContext(ctx))
+	if err != nil {
+		return fmt.Errorf("failed to create etcd session: %w", err)
+	}
+	defer func() {
+		if ctx.Err() != nil && !e.releaseOnCancel {
+			// Stop renewing the lease without revoking it, so that the key expires after the session TTL.
+			session.Orphan()
+			return
+		}
+		_ = session.Close()
+	}()
+
+	election := concurrency.NewElection(session, e.prefix)
+	go e.observe(ctx, election)
+
+	// The campaign does not notice when the session expires, so it is cancelled explicitly.
+	campaignCtx, cancelCampaign := context.WithCancel(ctx)
+	defer cancelCampaign()
+	go func() {
+		select {
+		case <-session.Done():
+			cancelCampaign()
+		case <-campaignCtx.Done():
+		}
+	}()
+	if err := election.Campaign(campaignCtx, e.identity); err != nil {
+		return err
+	}
+
+	e.setLeader(e.identity, true)
+	defer e.setLeader("", false)
+	e.logger.Info("Elected as leader in etcd", zap.String("key", election.Key()))
+
+	leaderCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go e.callbacks.OnStartedLeading(leaderCtx)
+
+	select {
+	case <-ctx.Done():
+		if e.releaseOnCancel {
+			resignCtx, cancelResign := context.WithTimeout(context.Background(), etcdResignTimeout)
+			defer cancelResign()
+			if err := election.Resign(resignCtx); err != nil {
+				e.logger.Warn("Failed to resign from etcd election", zap.Error(err))
+			}
+		}
+		return nil
+	case <-session.Done():
+		return errors.New("etcd session expired")
+	}
+}
+
+// observe keeps track of the current leader until ctx is cancelled.
+func (e *etcdElector) observe(ctx context.Context, election *concurrency.Election) {
+	for resp := range election.Observe(ctx) {
+		if len(resp.Kvs) == 0 {
+			continue
+		}
+		leader := string(resp.Kvs[0].Value)
+		e.lock.Lock()
+		e.leader = leader
+		e.lock.Unlock()
+		e.observer.observe(e.callbacks, leader)
+	}
+}
+
+func (e *etcdElector) getClient() (*clientv3.Client, error) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	if e.client != nil {
+		return e.client, nil
+	}
+
+	tlsConfig, err := e.cfg.TLS.LoadTLSConfig(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("failed to load etcd TLS config: %w", err)
+	}
+	client, err := clientv3.New(clientv3.Config{
+		Endpoints:   e.cfg.Endpoints,
+		DialTimeout: e.cfg.DialTimeout,
+		TLS:         tlsConfig,
+		Username:    e.cfg.Username,
+		Password:    string(e.cfg.Password),
+		Logger:      e.logger.Named("etcd"),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create etcd client: %w", err)
+	}
+	e.client = client
+	return client, nil
+}
+
+func (e *etcdElector) closeClient() {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	if e.client == nil {
+		return
+	}
+	if err := e.client.Close(); err != nil {
+		e.logger.Warn("Failed to close etcd client", zap.Error(err))
+	}
+	e.client = nil
+}
+
+func (e *etcdElector) setLeader(leader string, isLeader bool) {
+	e.lock.Lock()
+	if leader != "" {
+		e.leader = leader
+	}
+	e.isLeader = isLeader
+	e.lock.Unlock()
+
+	// The subscribers are notified after unlocking, so that they can query the elector.
+	if leader != "" {
+		e.observer.observe(e.callbacks, leader)
+	}
+}
+
+func (e *etcdElector) IsLeader() bool {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	return e.isLeader
+}
+
+func (e *etcdElector) GetLeader() string {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	return e.leader
+}
+
+func (e *etcdElector) Identity() string {
+	return e.identity
+}
@@ -0,0 +1,111 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package leaderelection
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.etcd.io/etcd/server/v3/embed"
+	"go.opentelemetry.io/collector/component/componenttest"
+)
+
+// startEmbeddedEtcd starts a single member etcd server and returns its client endpoint.
+func startEmbeddedEtcd(t *testing.T) string {
+	clientURL := url.URL{Scheme: "http", Host: freeAddress(t)}
+	peerURL := url.URL{Scheme: "http", Host: freeAddress(t)}
+
+	cfg := embed.NewConfig()
+	cfg.Dir = t.TempDir()
+	cfg.LogLevel = "error"
+	cfg.ListenClientUrls = []url.URL{clientURL}
+	cfg.AdvertiseClientUrls = []url.URL{clientURL}
+	cfg.ListenPeerUrls = []url.URL{peerURL}
+	cfg.AdvertisePeerUrls = []url.URL{peerURL}
+	cfg.InitialCluster = cfg.InitialClusterFromName(cfg.Name)
+
+	server, err := embed.StartEtcd(cfg)
+	require.NoError(t, err)
+	t.Cleanup(server.Close)
+
+	select {
+	case <-server.Server.ReadyNotify():
+	case <-time.After(10 * time.Second):
+		require.Fail(t, "etcd server did not become ready")
+	}
+	return clientURL.String()
+}
+
+func newTestEtcdElector(t *testing.T, endpoint, identity string) Elector {
+	cfg := NewDefaultConfig()
+	cfg.Backend = BackendEtcd
+	cfg.Identity = identity
+	cfg.RetryPeriod = 100 * time.Millisecond
+	cfg.Etcd.Endpoints = []string{endpoint}
+	cfg.Etcd.SessionTTL = 2 * time.Second
+	require.NoError(t, cfg.Validate())
+
+	elector, err := New(cfg, componenttest.NewNopTelemetrySettings(), Callbacks{
+		OnStartedLeading: func(context.Context) {},
+		OnStoppedLeading: func() {},
+	})
+	require.NoError(t, err)
+	return elector
+}
+
+func TestEtcdElectorFailover(t *testing.T) {
+	endpoint := startEmbeddedEtcd(t)
+
+	first := newTestEtcdElector(t, endpoint, "first")
+	stopFirst := runTestElector(t, first)
+	require.Eventually(t, first.IsLeader, 10*time.Second, 50*time.Millisecond)
+
+	second := newTestEtcdElector(t, endpoint, "second")
+	runTestElector(t, second)
+	require.Eventually(t, func() bool {
+		return second.GetLeader() == "first"
+	}, 10*time.Second, 50*time.Millisecond)
+	assert.False(t, second.IsLeader())
+
+	// The first candidate resigns on cancel, so the second one takes over without waiting for the session TTL.
+	stopFirst()
+	assert.False(t, first.IsLeader())
+	require.Eventually(t, second.IsLeader, time.Second, 20*time.Millisecond)
+	assert.Equal(t, "second", second.GetLeader())
+}
+
+func TestEtcdElectorCallbacksQueryElector(t *testing.T) {
+	endpoint := startEmbeddedEtcd(t)
+
+	cfg := NewDefaultConfig()
+	cfg.Backend = BackendEtcd
+	cfg.Identity = "first"
+	cfg.RetryPeriod = 100 * time.Millisecond
+	cfg.Etcd.Endpoints = []string{endpoint}
+	cfg.Etcd.SessionTTL = 2 * time.Second
+
+	// The elector must not be locked while the callbacks run.
+	var elector Elector
+	leaders := make(chan string, 10)
+	elector, err := New(cfg, componenttest.NewNopTelemetrySettings(), Callbacks{
+		OnStartedLeading: func(context.Context) {},
+		OnStoppedLeading: func() {},
+		OnNewLeader: func(string) {
+			leaders <- elector.GetLeader()
+		},
+	})
+	require.NoError(t, err)
+	runTestElector(t, elector)
+
+	select {
+	case leader := <-leaders:
+		assert.Equal(t, "first", leader)
+	case <-time.After(10 * time.Second):
+		require.Fail(t, "new leader was not observed")
+	}
+}
@@ -0,0 +1,315 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package leaderelection // import "github.com/skhalash/leaderreceivercreator/internal/leaderelection"
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.uber.org/zap"
+)
+
+// FileConfig defines the settings of the file backend.
+type FileConfig struct {
+	// Path is the path of the lease file shared by all candidates, e.g. on an NFS or host volume.
+	Path string `mapstructure:"path"`
+	// StaleAfter is the duration after which the lease is considered abandoned if the leader did not renew it.
+	StaleAfter time.Duration `mapstructure:"stale_after"`
+	// PollInterval is the interval at which the candidates try to acquire or renew the lease.
+	PollInterval time.Duration `mapstructure:"poll_interval"`
+}
+
+func (cfg *FileConfig) validate() error {
+	if cfg.Path == "" {
+		return errors.New("file::path must not be empty")
+	}
+	if cfg.StaleAfter <= 0 || cfg.PollInterval <= 0 {
+		return errors.New("file::stale_after and file::poll_interval must be positive")
+	}
+	if 2*cfg.PollInterval > cfg.StaleAfter {
+		return fmt.Errorf("file::poll_interval (%v) must not be greater than half of file::stale_after (%v)", cfg.PollInterval, cfg.StaleAfter)
+	}
+	return nil
+}
+
+// fileLeaseRecord is the content of the lease file.
+type fileLeaseRecord struct {
+	HolderIdentity string    `json:"holderIdentity"`
+	RenewTime      time.Time `json:"renewTime"`
+}
+
+// fileElector elects the leader using a lease file. Every update of the file is done while holding an advisory lock
+// on it, and the leader renews the timestamp in the file periodically, so that the lease can be taken over when
+// the leader dies without releasing it.
+type fileElector struct {
+	cfg             FileConfig
+	identity        string
+	releaseOnCancel bool
+	callbacks       Callbacks
+	logger          *zap.Logger
+	observer        leaderObserver
+
+	// observedRecord is the last record read from the lease file and observedTime the local time it was read
+	// at. The staleness of the lease is measured from observedTime, because the clock of the host that wrote
+	// the record might be skewed. They are only used by Run.
+	observedRecord fileLeaseRecord
+	observedTime   time.Time
+
+	lock     sync.Mutex
+	leader   string
+	isLeader bool
+}
+
+func newFileElector(cfg Config, set component.TelemetrySettings, callbacks Callbacks) (Elector, error) {
+	identity, err := candidateIdentity(cfg.Identity)
+	if err != nil {
+		return nil, err
+	}
+	return &fileElector{
+		cfg:             cfg.File,
+		identity:        identity,
+		releaseOnCancel: cfg.ReleaseOnCancel,
+		callbacks:       callbacks,
+		logger:          set.Logger,
+	}, nil
+}
+
+func (e *fileElector) Run(ctx context.Context) {
+	defer e.callbacks.OnStoppedLeading()
+
+	if !e.acquire(ctx) {
+		return
+	}
+
+	leaderCtx, cancel := context.WithCancel(ctx)
+	go e.callbacks.OnStartedLeading(leaderCtx)
+	e.renew(ctx)
+	cancel()
+
+	if ctx.Err() != nil && e.releaseOnCancel {
+		releaseCtx, cancelRelease := context.WithTimeout(context.Background(), e.cfg.PollInterval)
+		defer cancelRelease()
+		if err := e.release(releaseCtx); err != nil {
+			e.logger.Warn("Failed to release the lease file", zap.String("path", e.cfg.Path), zap.Error(err))
+		}
+	}
+}
+
+// acquire polls the lease file until the lease is acquired or ctx is cancelled.
+func (e *fileElector) acquire(ctx context.Context) bool {
+	ticker := time.NewTicker(e.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		attemptCtx, cancel := context.WithTimeout(ctx, e.cfg.PollInterval)
+		acquired, err := e.tryAcquireOrRenew(attemptCtx)
+		cancel()
+		if err != nil && ctx.Err() == nil {
+			e.logger.Warn("Failed to acquire the lease file", zap.String("path", e.cfg.Path), zap.Error(err))
+		}
+		if acquired {
+			e.logger.Info("Acquired the lease file", zap.String("path", e.cfg.Path))
+			return true
+		}
+
+		select {
+		case <-ctx.Done():
+			return false
+		case <-ticker.C:
+		}
+	}
+}
+
+// renew renews the lease until ctx is cancelled or the lease is lost. The lease is given up if it could not be
+// renewed for longer than stale_after minus poll_interval, so that the leader steps down before the other
+// candidates consider the lease abandoned. A renewal waiting for the lock of the file, e.g. on a hung NFS
+// mount, is abandoned at that deadline as well.
+func (e *fileElector) renew(ctx context.Context) {
+	ticker := time.NewTicker(e.cfg.PollInterval)
+	defer ticker.Stop()
+
+	lastRenew := time.Now()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		start := time.Now()
+		renewCtx, cancel := context.WithDeadline(ctx, lastRenew.Add(e.cfg.StaleAfter-e.cfg.PollInterval))
+		renewed, err := e.tryAcquireOrRenew(renewCtx)
+		cancel()
+		if ctx.Err() != nil {
+			return
+		}
+		e.callbacks.renewed(time.Since(start), err)
+		switch {
+		case renewed:
+			lastRenew = time.Now()
+		case err == nil:
+			e.logger.Info("Lease file was taken over", zap.String("path", e.cfg.Path), zap.String("leader", e.GetLeader()))
+			return
+		case time.Since(lastRenew) > e.cfg.StaleAfter-e.cfg.PollInterval:
+			e.logger.Error("Failed to renew the lease file in time", zap.String("path", e.cfg.Path), zap.Error(err))
+			e.setLeader(e.leaderIdentity(), false)
+			return
+		default:
+			e.logger.Warn("Failed to renew the lease file", zap.String("path", e.cfg.Path), zap.Error(err))
+		}
+	}
+}
+
+// tryAcquireOrRenew takes the lease if it is free, stale or already held by this candidate. The lease is
+// stale if the record did not change for stale_after, as observed by the local clock.
+func (e *fileElector) tryAcquireOrRenew(ctx context.Context) (bool, error) {
+	var acquired, observed bool
+	var leader string
+	err := e.withLockedFile(ctx, func(f *os.File) error {
+		record, err := readFileLeaseRecord(f)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		if record.HolderIdentity != e.observedRecord.HolderIdentity || !record.RenewTime.Equal(e.observedRecord.RenewTime) {
+			e.observedRecord = record
+			e.observedTime = now
+		}
+		if record.HolderIdentity != "" && record.HolderIdentity != e.identity && now.Sub(e.observedTime) <= e.cfg.StaleAfter {
+			leader, observed = record.HolderIdentity, true
+			e.recordLeader(leader, false)
+			return nil
+		}
+
+		record = fileLeaseRecord{HolderIdentity: e.identity, RenewTime: now}
+		if err := writeFileLeaseRecord(f, record); err != nil {
+			return err
+		}
+		e.observedRecord = record
+		e.observedTime = now
+		leader, observed = e.identity, true
+		e.recordLeader(leader, true)
+		acquired = true
+		return nil
+	})
+	// The subscribers are notified once the file is unlocked, so that a slow callback does not stall the other
+	// candidates.
+	if observed {
+		e.observer.observe(e.callbacks, leader)
+	}
+	return acquired, err
+}
+
+// release clears the lease file if it is still held by this candidate.
+func (e *fileElector) release(ctx context.Context) error {
+	var released bool
+	err := e.withLockedFile(ctx, func(f *os.File) error {
+		record, err := readFileLeaseRecord(f)
+		if err != nil {
+			return err
+		}
+		if record.HolderIdentity != e.identity {
+			return nil
+		}
+		if err := writeFileLeaseRecord(f, fileLeaseRecord{}); err != nil {
+			return err
+		}
+		e.recordLeader("", false)
+		released = true
+		return nil
+	})
+	if released {
+		e.observer.observe(e.callbacks, "")
+	}
+	return err
+}
+
+// withLockedFile calls fn with the lease file opened and exclusively locked. It fails if the lock cannot be
+// taken before ctx is done.
+func (e *fileElector) withLockedFile(ctx context.Context, fn func(f *os.File) error) error {
+	f, err := os.OpenFile(e.cfg.Path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err := lockFile(ctx, f); err != nil {
+		return fmt.Errorf("failed to lock %s: %w", e.cfg.Path, err)
+	}
+	defer func() {
+		_ = unlockFile(f)
+	}()
+
+	return fn(f)
+}
+
+func readFileLeaseRecord(f *os.File) (fileLeaseRecord, error) {
+	var record fileLeaseRecord
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return record, err
+	}
+	if len(data) == 0 {
+		return record, nil
+	}
+	if err := json.Unmarshal(data, &record); err != nil {
+		return record, fmt.Errorf("failed to parse lease file: %w", err)
+	}
+	return record, nil
+}
+
+func writeFileLeaseRecord(f *os.File, record fileLeaseRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	if err := f.Truncate(0); err != nil {
+		return err
+	}
+	if _, err := f.WriteAt(data, 0); err != nil {
+		return err
+	}
+	return f.Sync()
+}
+
+func (e *fileElector) setLeader(leader string, isLeader bool) {
+	e.recordLeader(leader, isLeader)
+	e.observer.observe(e.callbacks, leader)
+}
+
+// recordLeader updates the leader without notifying the subscribers, which is safe while the file is locked.
+func (e *fileElector) recordLeader(leader string, isLeader bool) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.leader = leader
+	e.isLeader = isLeader
+}
+
+func (e *fileElector) leaderIdentity() string {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	return e.leader
+}
+
+func (e *fileElector) IsLeader() bool {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	return e.isLeader
+}
+
+func (e *fileElector) GetLeader() string {
+	return e.leaderIdentity()
+}
+
+func (e *fileElector) Identity() string {
+	return e.identity
+}
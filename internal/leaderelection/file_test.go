@@ -0,0 +1,202 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+
+package leaderelection
+
+import (
+	"bufio"
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+)
+
+const fileHelperPathEnv = "LEADER_ELECTION_FILE_HELPER_PATH"
+
+func newTestFileElector(t *testing.T, path, identity string, started chan struct{}) Elector {
+	cfg := NewDefaultConfig()
+	cfg.Backend = BackendFile
+	cfg.Identity = identity
+	cfg.File = FileConfig{
+		Path:         path,
+		StaleAfter:   500 * time.Millisecond,
+		PollInterval: 50 * time.Millisecond,
+	}
+	require.NoError(t, cfg.Validate())
+
+	elector, err := New(cfg, componenttest.NewNopTelemetrySettings(), Callbacks{
+		OnStartedLeading: func(context.Context) { close(started) },
+		OnStoppedLeading: func() {},
+	})
+	require.NoError(t, err)
+	return elector
+}
+
+func TestFileElectorFailoverOnRelease(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "leader.lease")
+
+	firstStarted := make(chan struct{})
+	first := newTestFileElector(t, path, "first", firstStarted)
+	secondStarted := make(chan struct{})
+	second := newTestFileElector(t, path, "second", secondStarted)
+
+	stopFirst := runTestElector(t, first)
+	<-firstStarted
+
+	runTestElector(t, second)
+
+	assert.Never(t, second.IsLeader, 300*time.Millisecond, 50*time.Millisecond)
+	assert.Equal(t, "first", second.GetLeader())
+
+	stopFirst()
+	assert.False(t, first.IsLeader())
+
+	// The lease is released, so the second candidate takes over without waiting for it to become stale.
+	select {
+	case <-secondStarted:
+	case <-time.After(250 * time.Millisecond):
+		require.Fail(t, "second candidate was not elected")
+	}
+	assert.True(t, second.IsLeader())
+}
+
+func TestFileElectorIgnoresRemoteClock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "leader.lease")
+	// The lease was renewed just now by a host whose clock is an hour behind.
+	f, err := os.Create(path)
+	require.NoError(t, err)
+	require.NoError(t, writeFileLeaseRecord(f, fileLeaseRecord{HolderIdentity: "other", RenewTime: time.Now().Add(-time.Hour)}))
+	require.NoError(t, f.Close())
+
+	started := make(chan struct{})
+	elector := newTestFileElector(t, path, "local", started)
+	runTestElector(t, elector)
+
+	// The lease is only taken over once it did not change for stale_after.
+	assert.Never(t, elector.IsLeader, 300*time.Millisecond, 50*time.Millisecond)
+	assert.Equal(t, "other", elector.GetLeader())
+	select {
+	case <-started:
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "local candidate was not elected")
+	}
+}
+
+func TestFileElectorStepsDownWhenLockIsStuck(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "leader.lease")
+
+	started := make(chan struct{})
+	elector := newTestFileElector(t, path, "local", started)
+	stop := runTestElector(t, elector)
+	<-started
+
+	// Another open file description holding the lock stands for a hung mount.
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+	require.NoError(t, syscall.Flock(int(f.Fd()), syscall.LOCK_EX))
+	// The candidate must not be elected again once the lock is released.
+	defer stop()
+
+	assert.Eventually(t, func() bool { return !elector.IsLeader() }, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestFileElectorNotifiesAfterUnlocking(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "leader.lease")
+	cfg := NewDefaultConfig()
+	cfg.Backend = BackendFile
+	cfg.File = FileConfig{
+		Path:         path,
+		StaleAfter:   500 * time.Millisecond,
+		PollInterval: 50 * time.Millisecond,
+	}
+
+	// The callback fails to take the lock if it is called while the candidate holds it.
+	locked := make(chan error, 1)
+	elector, err := New(cfg, componenttest.NewNopTelemetrySettings(), Callbacks{
+		OnStartedLeading: func(context.Context) {},
+		OnStoppedLeading: func() {},
+		OnNewLeader: func(string) {
+			f, err := os.Open(path)
+			if err == nil {
+				defer f.Close()
+				err = syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+			}
+			select {
+			case locked <- err:
+			default:
+			}
+		},
+	})
+	require.NoError(t, err)
+	runTestElector(t, elector)
+
+	select {
+	case err := <-locked:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "new leader was not observed")
+	}
+}
+
+// TestFileElectorHelperProcess is not a real test, it runs a candidate in a separate process
+// for TestFileElectorMultipleProcesses.
+func TestFileElectorHelperProcess(t *testing.T) {
+	path := os.Getenv(fileHelperPathEnv)
+	if path == "" {
+		t.Skip("only runs as a helper process")
+	}
+
+	started := make(chan struct{})
+	elector := newTestFileElector(t, path, "helper", started)
+	go elector.Run(context.Background())
+
+	<-started
+	_, _ = os.Stdout.WriteString("leading\n")
+	select {}
+}
+
+func TestFileElectorMultipleProcesses(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "leader.lease")
+
+	cmd := exec.Command(os.Args[0], "-test.run=^TestFileElectorHelperProcess$")
+	cmd.Env = append(os.Environ(), fileHelperPathEnv+"="+path)
+	stdout, err := cmd.StdoutPipe()
+	require.NoError(t, err)
+	require.NoError(t, cmd.Start())
+	defer func() {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+	}()
+
+	scanner := bufio.NewScanner(stdout)
+	require.True(t, scanner.Scan())
+	require.Equal(t, "leading", scanner.Text())
+
+	started := make(chan struct{})
+	elector := newTestFileElector(t, path, "local", started)
+	runTestElector(t, elector)
+
+	assert.Never(t, elector.IsLeader, 500*time.Millisecond, 50*time.Millisecond)
+	assert.Equal(t, "helper", elector.GetLeader())
+
+	// A killed leader does not release the lease, so it is taken over once it becomes stale.
+	require.NoError(t, cmd.Process.Kill())
+	_ = cmd.Wait()
+
+	select {
+	case <-started:
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "local candidate was not elected")
+	}
+	assert.Equal(t, "local", elector.GetLeader())
+}
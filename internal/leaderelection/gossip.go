@@ -0,0 +1,290 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package leaderelection // import "github.com/skhalash/leaderreceivercreator/internal/leaderelection"
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/memberlist"
+	"go.opentelemetry.io/collector/component"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultGossipBindAddress = "0.0.0.0:7946"
+	gossipLeaveTimeout       = 5 * time.Second
+)
+
+// GossipConfig defines the settings of the gossip backend.
+type GossipConfig struct {
+	// BindAddress is the address the gossip protocol listens on, both for TCP and UDP.
+	BindAddress string `mapstructure:"bind_address"`
+	// AdvertiseAddress is the address advertised to the other members. Defaults to the address of
+	// a private network interface when BindAddress is unspecified.
+	AdvertiseAddress string `mapstructure:"advertise_address"`
+	// Seeds are the addresses of the members to join. DNS names resolving to several members,
+	// e.g. of a headless Kubernetes service, are supported.
+	Seeds []string `mapstructure:"seeds"`
+}
+
+func (cfg *GossipConfig) validate() error {
+	if _, _, err := splitHostPort(cfg.BindAddress); err != nil {
+		return fmt.Errorf("invalid gossip::bind_address %q: %w", cfg.BindAddress, err)
+	}
+	if cfg.AdvertiseAddress != "" {
+		if _, _, err := splitHostPort(cfg.AdvertiseAddress); err != nil {
+			return fmt.Errorf("invalid gossip::advertise_address %q: %w", cfg.AdvertiseAddress, err)
+		}
+	}
+	return nil
+}
+
+func splitHostPort(address string) (string, int, error) {
+	host, portStr, err := net.SplitHostPort(address)
+	if err != nil {
+		return "", 0, err
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid port %q", portStr)
+	}
+	return host, port, nil
+}
+
+// gossipElector elects the leader among the members of a gossip cluster. Every member maintains a view of the live
+// members and considers the member with the lowest identity as the leader, so all members agree on the leader
+// once their views converge. A member waits for retry_period after joining before it takes over the leadership,
+// so that the previous leader has time to observe the new member and step down.
+type gossipElector struct {
+	cfg             GossipConfig
+	identity        string
+	retryPeriod     time.Duration
+	releaseOnCancel bool
+	callbacks       Callbacks
+	logger          *zap.Logger
+	observer        leaderObserver
+
+	// changed is notified when the membership changes.
+	changed chan struct{}
+
+	lock     sync.Mutex
+	list     *memberlist.Memberlist
+	joinedAt time.Time
+	leader   string
+	isLeader bool
+}
+
+func newGossipElector(cfg Config, set component.TelemetrySettings, callbacks Callbacks) (Elector, error) {
+	identity, err := candidateIdentity(cfg.Identity)
+	if err != nil {
+		return nil, err
+	}
+	return &gossipElector{
+		cfg:             cfg.Gossip,
+		identity:        identity,
+		retryPeriod:     cfg.RetryPeriod,
+		releaseOnCancel: cfg.ReleaseOnCancel,
+		callbacks:       callbacks,
+		logger:          set.Logger,
+		changed:         make(chan struct{}, 1),
+	}, nil
+}
+
+func (e *gossipElector) Run(ctx context.Context) {
+	defer e.callbacks.OnStoppedLeading()
+
+	if err := e.start(); err != nil {
+		e.logger.Error("Failed to start gossip member", zap.Error(err))
+		// Wait before returning to not restart the member in a tight loop.
+		select {
+		case <-ctx.Done():
+		case <-time.After(e.retryPeriod):
+		}
+		return
+	}
+
+	ticker := time.NewTicker(e.retryPeriod)
+	defer ticker.Stop()
+
+	for !e.evaluate() {
+		select {
+		case <-ctx.Done():
+			e.stop()
+			return
+		case <-e.changed:
+		case <-ticker.C:
+			e.rejoin()
+		}
+	}
+
+	leaderCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go e.callbacks.OnStartedLeading(leaderCtx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			e.stop()
+			return
+		case <-e.changed:
+		case <-ticker.C:
+			e.rejoin()
+		}
+		if !e.evaluate() {
+			e.logger.Info("Member with a lower identity joined, stepping down", zap.String("leader", e.GetLeader()))
+			return
+		}
+	}
+}
+
+// start creates the gossip member and joins the seeds unless it is already running.
+func (e *gossipElector) start() error {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	if e.list != nil {
+		return nil
+	}
+
+	bindHost, bindPort, err := splitHostPort(e.cfg.BindAddress)
+	if err != nil {
+		return err
+	}
+
+	mlConfig := memberlist.DefaultLANConfig()
+	mlConfig.Name = e.identity
+	mlConfig.BindAddr = bindHost
+	mlConfig.BindPort = bindPort
+	mlConfig.AdvertisePort = bindPort
+	if e.cfg.AdvertiseAddress != "" {
+		advertiseHost, advertisePort, err := splitHostPort(e.cfg.AdvertiseAddress)
+		if err != nil {
+			return err
+		}
+		mlConfig.AdvertiseAddr = advertiseHost
+		mlConfig.AdvertisePort = advertisePort
+	}
+	mlConfig.Events = &gossipEventDelegate{changed: e.changed}
+	mlConfig.Logger, _ = zap.NewStdLogAt(e.logger.Named("memberlist"), zap.DebugLevel)
+	mlConfig.LogOutput = nil
+
+	list, err := memberlist.Create(mlConfig)
+	if err != nil {
+		return fmt.Errorf("failed to create gossip member: %w", err)
+	}
+	e.list = list
+	e.joinedAt = time.Now()
+	e.joinLocked()
+	return nil
+}
+
+// rejoin joins the seeds again if no other member is known, e.g. because the seeds were not up yet on start.
+func (e *gossipElector) rejoin() {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	if e.list != nil && e.list.NumMembers() == 1 {
+		e.joinLocked()
+	}
+}
+
+func (e *gossipElector) joinLocked() {
+	if len(e.cfg.Seeds) == 0 {
+		return
+	}
+	if _, err := e.list.Join(e.cfg.Seeds); err != nil {
+		e.logger.Debug("Failed to join gossip seeds", zap.Strings("seeds", e.cfg.Seeds), zap.Error(err))
+	}
+}
+
+// evaluate updates the leader from the current membership view and returns true if this member is the leader.
+func (e *gossipElector) evaluate() bool {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	if e.list == nil {
+		e.leader, e.isLeader = "", false
+		return false
+	}
+
+	leader := e.identity
+	for _, member := range e.list.Members() {
+		if member.Name < leader {
+			leader = member.Name
+		}
+	}
+
+	e.leader = leader
+	e.isLeader = leader == e.identity && time.Since(e.joinedAt) >= e.retryPeriod
+	e.observer.observe(e.callbacks, leader)
+	return e.isLeader
+}
+
+// stop leaves the cluster if configured and shuts the member down.
+func (e *gossipElector) stop() {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	if e.list == nil {
+		return
+	}
+
+	if e.releaseOnCancel {
+		if err := e.list.Leave(gossipLeaveTimeout); err != nil {
+			e.logger.Warn("Failed to leave gossip cluster", zap.Error(err))
+		}
+	}
+	if err := e.list.Shutdown(); err != nil {
+		e.logger.Warn("Failed to shut down gossip member", zap.Error(err))
+	}
+	e.list = nil
+	e.leader, e.isLeader = "", false
+}
+
+func (e *gossipElector) IsLeader() bool {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	return e.isLeader
+}
+
+func (e *gossipElector) GetLeader() string {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	return e.leader
+}
+
+func (e *gossipElector) Identity() string {
+	return e.identity
+}
+
+var _ memberlist.EventDelegate = (*gossipEventDelegate)(nil)
+
+// gossipEventDelegate notifies about membership changes without blocking memberlist.
+type gossipEventDelegate struct {
+	changed chan struct{}
+}
+
+func (d *gossipEventDelegate) notify() {
+	select {
+	case d.changed <- struct{}{}:
+	default:
+	}
+}
+
+func (d *gossipEventDelegate) NotifyJoin(*memberlist.Node) {
+	d.notify()
+}
+
+func (d *gossipEventDelegate) NotifyLeave(*memberlist.Node) {
+	d.notify()
+}
+
+func (d *gossipEventDelegate) NotifyUpdate(*memberlist.Node) {
+	d.notify()
+}
@@ -0,0 +1,90 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package leaderelection
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+)
+
+func TestGossipElectorThreeMembers(t *testing.T) {
+	identities := []string{"collector-a", "collector-b", "collector-c"}
+	addresses := []string{freeAddress(t), freeAddress(t), freeAddress(t)}
+
+	electors := make([]Elector, len(identities))
+	stops := make([]func(), len(identities))
+	for i, identity := range identities {
+		cfg := NewDefaultConfig()
+		cfg.Backend = BackendGossip
+		cfg.Identity = identity
+		cfg.RetryPeriod = 100 * time.Millisecond
+		cfg.Gossip = GossipConfig{
+			BindAddress: addresses[i],
+			Seeds:       []string{addresses[0]},
+		}
+		require.NoError(t, cfg.Validate())
+
+		elector, err := New(cfg, componenttest.NewNopTelemetrySettings(), Callbacks{
+			OnStartedLeading: func(context.Context) {},
+			OnStoppedLeading: func() {},
+		})
+		require.NoError(t, err)
+		electors[i] = elector
+		stops[i] = runTestElector(t, elector)
+	}
+
+	// The member with the lowest identity is elected once the membership views converge.
+	for _, elector := range electors {
+		require.Eventually(t, func() bool {
+			return elector.GetLeader() == "collector-a"
+		}, 10*time.Second, 50*time.Millisecond)
+	}
+	require.Eventually(t, electors[0].IsLeader, 5*time.Second, 50*time.Millisecond)
+	assert.False(t, electors[1].IsLeader())
+	assert.False(t, electors[2].IsLeader())
+
+	stops[0]()
+
+	require.Eventually(t, electors[1].IsLeader, 10*time.Second, 50*time.Millisecond)
+	assert.False(t, electors[2].IsLeader())
+	assert.Equal(t, "collector-b", electors[2].GetLeader())
+}
+
+func TestGossipElectorStepsDownForLowerMember(t *testing.T) {
+	addresses := []string{freeAddress(t), freeAddress(t)}
+
+	newElector := func(identity, address string) Elector {
+		cfg := NewDefaultConfig()
+		cfg.Backend = BackendGossip
+		cfg.Identity = identity
+		cfg.RetryPeriod = 100 * time.Millisecond
+		cfg.Gossip = GossipConfig{
+			BindAddress: address,
+			Seeds:       addresses,
+		}
+		elector, err := New(cfg, componenttest.NewNopTelemetrySettings(), Callbacks{
+			OnStartedLeading: func(context.Context) {},
+			OnStoppedLeading: func() {},
+		})
+		require.NoError(t, err)
+		return elector
+	}
+
+	higher := newElector("collector-b", addresses[1])
+	runTestElector(t, higher)
+	require.Eventually(t, higher.IsLeader, 5*time.Second, 50*time.Millisecond)
+
+	lower := newElector("collector-a", addresses[0])
+	runTestElector(t, lower)
+
+	require.Eventually(t, func() bool {
+		return lower.IsLeader() && !higher.IsLeader()
+	}, 10*time.Second, 50*time.Millisecond)
+	assert.Equal(t, "collector-a", higher.GetLeader())
+}
@@ -0,0 +1,340 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package leaderelection // import "github.com/skhalash/leaderreceivercreator/internal/leaderelection"
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	"go.opentelemetry.io/collector/component"
+	"go.uber.org/zap"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	k8sleaderelection "k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+)
+
+const (
+	// AuthTypeServiceAccount authenticates to the Kubernetes API with the service account of the pod.
+	AuthTypeServiceAccount = "serviceAccount"
+	// AuthTypeKubeConfig authenticates to the Kubernetes API with a kubeconfig file.
+	AuthTypeKubeConfig = "kubeConfig"
+	// AuthTypeNone connects to the Kubernetes API of the cluster without authentication.
+	AuthTypeNone = "none"
+
+	inClusterNamespacePath = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
+	podNamespaceEnv        = "POD_NAMESPACE"
+	defaultLeaseNamespace  = "default"
+
+	defaultK8sAPIQPS   = 5
+	defaultK8sAPIBurst = 10
+
+	defaultK8sAPIRetryInitialInterval = time.Second
+	defaultK8sAPIRetryMaxInterval     = 30 * time.Second
+)
+
+// K8sAPIConfig defines the settings of the Kubernetes API client used by the kubernetes backend.
+type K8sAPIConfig struct {
+	// AuthType is the method used to authenticate to the Kubernetes API: serviceAccount, kubeConfig or none.
+	AuthType string `mapstructure:"auth_type"`
+	// KubeConfig is the path of the kubeconfig file used by the kubeConfig auth type. Defaults to the
+	// KUBECONFIG environment variable or to ~/.kube/config.
+	KubeConfig string `mapstructure:"kubeconfig"`
+	// Context is the kubeconfig context used by the kubeConfig auth type. Defaults to the current context.
+	Context string `mapstructure:"context"`
+	// QPS is the maximum number of queries per second sent to the Kubernetes API.
+	QPS float32 `mapstructure:"qps"`
+	// Burst is the maximum burst of queries sent to the Kubernetes API.
+	Burst int `mapstructure:"burst"`
+	// Timeout is the timeout of the requests to the Kubernetes API. No timeout is applied if zero.
+	Timeout time.Duration `mapstructure:"timeout"`
+	// UserAgent is the user agent of the requests to the Kubernetes API. Defaults to the one of client-go.
+	UserAgent string `mapstructure:"user_agent"`
+	// RetryOnFailure retries creating the client and accessing the Lease in the background instead of
+	// failing the start of the component.
+	RetryOnFailure K8sAPIRetryConfig `mapstructure:"retry_on_failure"`
+}
+
+// K8sAPIRetryConfig defines the exponential backoff between the attempts to reach the Kubernetes API.
+type K8sAPIRetryConfig struct {
+	// Enabled enables the retries. If disabled, the component fails to start if the client cannot be created.
+	Enabled bool `mapstructure:"enabled"`
+	// InitialInterval is the time to wait after the first failure.
+	InitialInterval time.Duration `mapstructure:"initial_interval"`
+	// MaxInterval is the upper bound of the time to wait between the attempts.
+	MaxInterval time.Duration `mapstructure:"max_interval"`
+}
+
+func (cfg *K8sAPIRetryConfig) validate() error {
+	if !cfg.Enabled {
+		return nil
+	}
+	if cfg.InitialInterval <= 0 || cfg.MaxInterval <= 0 {
+		return errors.New("k8s_api::retry_on_failure::initial_interval and k8s_api::retry_on_failure::max_interval must be positive")
+	}
+	if cfg.InitialInterval > cfg.MaxInterval {
+		return fmt.Errorf("k8s_api::retry_on_failure::initial_interval (%v) must not be greater than k8s_api::retry_on_failure::max_interval (%v)",
+			cfg.InitialInterval, cfg.MaxInterval)
+	}
+	return nil
+}
+
+// newBackOff returns an exponential backoff that never gives up.
+func (cfg *K8sAPIRetryConfig) newBackOff() backoff.BackOff {
+	b := backoff.NewExponentialBackOff()
+	b.InitialInterval = cfg.InitialInterval
+	b.MaxInterval = cfg.MaxInterval
+	b.MaxElapsedTime = 0
+	b.Reset()
+	return b
+}
+
+func (cfg *K8sAPIConfig) validate() error {
+	switch cfg.AuthType {
+	case AuthTypeServiceAccount, AuthTypeNone:
+		if cfg.KubeConfig != "" || cfg.Context != "" {
+			return fmt.Errorf("k8s_api::kubeconfig and k8s_api::context require the %s auth_type", AuthTypeKubeConfig)
+		}
+	case AuthTypeKubeConfig:
+	default:
+		return fmt.Errorf("unsupported k8s_api::auth_type %q", cfg.AuthType)
+	}
+	if cfg.QPS <= 0 || cfg.Burst <= 0 {
+		return errors.New("k8s_api::qps and k8s_api::burst must be positive")
+	}
+	if cfg.Timeout < 0 {
+		return fmt.Errorf("k8s_api::timeout (%v) must not be negative", cfg.Timeout)
+	}
+	return cfg.RetryOnFailure.validate()
+}
+
+// newKubernetesElector creates a leader elector backed by a Kubernetes Lease. If retry_on_failure is enabled,
+// the client is created by the election loop, so that the component starts even if it cannot be created yet.
+func newKubernetesElector(cfg Config, _ component.TelemetrySettings, callbacks Callbacks) (Elector, error) {
+	// Leader id, needs to be unique, use pod name in kubernetes case.
+	identity, err := candidateIdentity(cfg.Identity)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.K8sAPI.RetryOnFailure.Enabled {
+		return newRetryingKubernetesElector(cfg, identity, callbacks), nil
+	}
+
+	client, err := newClient(cfg.K8sAPI)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Kubernetes client: %w", err)
+	}
+	leaderElector, err := newLeaderElector(client, cfg, identity, callbacks)
+	if err != nil {
+		return nil, err
+	}
+	return leaderElector, nil
+}
+
+func newClient(cfg K8sAPIConfig) (kubernetes.Interface, error) {
+	config, err := newRestConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return kubernetes.NewForConfig(config)
+}
+
+// newRestConfig creates the configuration of the Kubernetes API client for the auth type.
+func newRestConfig(cfg K8sAPIConfig) (*rest.Config, error) {
+	var (
+		config *rest.Config
+		err    error
+	)
+	switch cfg.AuthType {
+	case AuthTypeServiceAccount:
+		config, err = rest.InClusterConfig()
+	case AuthTypeKubeConfig:
+		loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+		loadingRules.ExplicitPath = cfg.KubeConfig
+		overrides := &clientcmd.ConfigOverrides{CurrentContext: cfg.Context}
+		config, err = clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, overrides).ClientConfig()
+	case AuthTypeNone:
+		host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+		if host == "" || port == "" {
+			return nil, errors.New("unable to load the address of the Kubernetes API, KUBERNETES_SERVICE_HOST and KUBERNETES_SERVICE_PORT must be defined")
+		}
+		config = &rest.Config{
+			Host:            "https://" + net.JoinHostPort(host, port),
+			TLSClientConfig: rest.TLSClientConfig{Insecure: true},
+		}
+	default:
+		return nil, fmt.Errorf("unsupported auth_type %q", cfg.AuthType)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	config.QPS = cfg.QPS
+	config.Burst = cfg.Burst
+	config.Timeout = cfg.Timeout
+	if cfg.UserAgent != "" {
+		config.UserAgent = cfg.UserAgent
+	}
+	return config, nil
+}
+
+// leaseNamespace returns the namespace of the lease. Unless it is configured, the lease is placed in the
+// namespace of the service account when running in-cluster, then in the namespace in the POD_NAMESPACE
+// environment variable, and in the default namespace otherwise.
+func leaseNamespace(configured, serviceAccountNamespacePath string, logger *zap.Logger) string {
+	namespace, source := configured, "config"
+	if namespace == "" {
+		namespace, source = detectNamespace(serviceAccountNamespacePath)
+	}
+	logger.Info("Using lease namespace", zap.String("namespace", namespace), zap.String("source", source))
+	return namespace
+}
+
+func detectNamespace(serviceAccountNamespacePath string) (namespace string, source string) {
+	if data, err := os.ReadFile(serviceAccountNamespacePath); err == nil {
+		if namespace := strings.TrimSpace(string(data)); namespace != "" {
+			return namespace, "service account"
+		}
+	}
+	if namespace := os.Getenv(podNamespaceEnv); namespace != "" {
+		return namespace, podNamespaceEnv
+	}
+	return defaultLeaseNamespace, "default"
+}
+
+// NewResourceLock creates a new leases resource lock for use in a leader election loop
+func newResourceLock(client kubernetes.Interface, leaderElectionNamespace, lockName, identity string) (resourcelock.Interface, error) {
+	return resourcelock.New(
+		resourcelock.LeasesResourceLock,
+		leaderElectionNamespace,
+		lockName,
+		client.CoreV1(),
+		client.CoordinationV1(),
+		resourcelock.ResourceLockConfig{
+			Identity: identity,
+		})
+}
+
+// kubernetesElector is the client-go leader elector along with the identity of the candidate.
+type kubernetesElector struct {
+	*k8sleaderelection.LeaderElector
+	identity string
+}
+
+func (e *kubernetesElector) Identity() string {
+	return e.identity
+}
+
+// newLeaderElector return  a leader elector object using client-go
+func newLeaderElector(
+	client kubernetes.Interface,
+	cfg Config,
+	identity string,
+	callbacks Callbacks,
+) (*kubernetesElector, error) {
+	resourceLock, err := newResourceLock(client, cfg.LeaseNamespace, cfg.LeaseName, identity)
+	if err != nil {
+		return nil, err
+	}
+
+	lock := &renewObservingLock{Interface: resourceLock, callbacks: callbacks}
+	if cfg.K8sAPI.RetryOnFailure.Enabled {
+		lock.backOff = cfg.K8sAPI.RetryOnFailure.newBackOff()
+	}
+	leConfig := k8sleaderelection.LeaderElectionConfig{
+		Lock:          lock,
+		LeaseDuration: cfg.LeaseDuration,
+		RenewDeadline: cfg.RenewDeadline,
+		RetryPeriod:   cfg.RetryPeriod,
+		// Callers must stop the leader-gated work before cancelling Run,
+		// otherwise another candidate may take over while it is still running.
+		ReleaseOnCancel: cfg.ReleaseOnCancel,
+		Callbacks: k8sleaderelection.LeaderCallbacks{
+			OnStartedLeading: callbacks.OnStartedLeading,
+			OnStoppedLeading: callbacks.OnStoppedLeading,
+			OnNewLeader:      callbacks.newLeader,
+		},
+	}
+
+	leaderElector, err := k8sleaderelection.NewLeaderElector(leConfig)
+	if err != nil {
+		return nil, err
+	}
+	return &kubernetesElector{LeaderElector: leaderElector, identity: resourceLock.Identity()}, nil
+}
+
+// renewObservingLock reports the latency of the updates of the Lease made while the candidate holds it.
+// client-go calls the lock from a single goroutine, so it needs no synchronization.
+//
+// It also reports whether the Lease can be accessed while the candidate does not hold it. If a backoff is
+// set, it backs off from reading the Lease after a failure, in addition to the retry period of client-go.
+type renewObservingLock struct {
+	resourcelock.Interface
+	callbacks Callbacks
+	// holding is true if the candidate held the Lease when it was last read or written.
+	holding bool
+
+	backOff backoff.BackOff
+	// failing is true if the last access to the Lease failed.
+	failing bool
+}
+
+func (l *renewObservingLock) Get(ctx context.Context) (*resourcelock.LeaderElectionRecord, []byte, error) {
+	if !l.holding && l.failing && l.backOff != nil {
+		if err := waitBackOff(ctx, l.backOff); err != nil {
+			return nil, nil, err
+		}
+	}
+	record, raw, err := l.Interface.Get(ctx)
+	// The Lease does not exist until the first candidate creates it.
+	if !l.holding && !apierrors.IsNotFound(err) {
+		l.accessed(err)
+	}
+	l.holding = err == nil && record.HolderIdentity == l.Identity()
+	return record, raw, err
+}
+
+func (l *renewObservingLock) Create(ctx context.Context, record resourcelock.LeaderElectionRecord) error {
+	err := l.Interface.Create(ctx, record)
+	// Another candidate may create the Lease first.
+	if !apierrors.IsAlreadyExists(err) {
+		l.accessed(err)
+	}
+	l.holding = err == nil && record.HolderIdentity == l.Identity()
+	return err
+}
+
+func (l *renewObservingLock) Update(ctx context.Context, record resourcelock.LeaderElectionRecord) error {
+	start := time.Now()
+	err := l.Interface.Update(ctx, record)
+	// Releasing the Lease also updates it, but with an empty holder.
+	if l.holding && record.HolderIdentity == l.Identity() {
+		l.callbacks.renewed(time.Since(start), err)
+	} else if !l.holding && !apierrors.IsConflict(err) {
+		l.accessed(err)
+	}
+	l.holding = err == nil && record.HolderIdentity == l.Identity()
+	return err
+}
+
+// accessed reports the transitions between failing and successful accesses to the Lease.
+func (l *renewObservingLock) accessed(err error) {
+	failing := err != nil
+	if !failing && l.backOff != nil {
+		l.backOff.Reset()
+	}
+	if l.failing == failing {
+		return
+	}
+	l.failing = failing
+	l.callbacks.backendError(err)
+}
@@ -0,0 +1,120 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package leaderelection // import "github.com/skhalash/leaderreceivercreator/internal/leaderelection"
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	"k8s.io/client-go/kubernetes"
+)
+
+// retryingKubernetesElector creates the client-go leader elector in the election loop, retrying with an
+// exponential backoff until the client can be created.
+type retryingKubernetesElector struct {
+	cfg       Config
+	identity  string
+	callbacks Callbacks
+	// newClient is replaced in tests.
+	newClient func(cfg K8sAPIConfig) (kubernetes.Interface, error)
+
+	lock    sync.Mutex
+	elector *kubernetesElector
+}
+
+func newRetryingKubernetesElector(cfg Config, identity string, callbacks Callbacks) *retryingKubernetesElector {
+	return &retryingKubernetesElector{
+		cfg:       cfg,
+		identity:  identity,
+		callbacks: callbacks,
+		newClient: newClient,
+	}
+}
+
+func (e *retryingKubernetesElector) Run(ctx context.Context) {
+	elector, err := e.connect(ctx)
+	if err != nil {
+		// Like client-go, report that the campaign ended even though it never started.
+		if e.callbacks.OnStoppedLeading != nil {
+			e.callbacks.OnStoppedLeading()
+		}
+		return
+	}
+	elector.Run(ctx)
+}
+
+// connect returns the client-go leader elector, creating it first if needed. It only fails if ctx is
+// cancelled.
+func (e *retryingKubernetesElector) connect(ctx context.Context) (*kubernetesElector, error) {
+	if elector := e.getElector(); elector != nil {
+		return elector, nil
+	}
+
+	b := e.cfg.K8sAPI.RetryOnFailure.newBackOff()
+	for failed := false; ; failed = true {
+		elector, err := e.newElector()
+		if err == nil {
+			e.lock.Lock()
+			e.elector = elector
+			e.lock.Unlock()
+			if failed {
+				e.callbacks.backendError(nil)
+			}
+			return elector, nil
+		}
+
+		e.callbacks.backendError(err)
+		if err := waitBackOff(ctx, b); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (e *retryingKubernetesElector) newElector() (*kubernetesElector, error) {
+	client, err := e.newClient(e.cfg.K8sAPI)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Kubernetes client: %w", err)
+	}
+	return newLeaderElector(client, e.cfg, e.identity, e.callbacks)
+}
+
+func (e *retryingKubernetesElector) getElector() *kubernetesElector {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	return e.elector
+}
+
+func (e *retryingKubernetesElector) IsLeader() bool {
+	if elector := e.getElector(); elector != nil {
+		return elector.IsLeader()
+	}
+	return false
+}
+
+func (e *retryingKubernetesElector) GetLeader() string {
+	if elector := e.getElector(); elector != nil {
+		return elector.GetLeader()
+	}
+	return ""
+}
+
+func (e *retryingKubernetesElector) Identity() string {
+	return e.identity
+}
+
+// waitBackOff waits for the next backoff interval or until ctx is cancelled.
+func waitBackOff(ctx context.Context, b backoff.BackOff) error {
+	timer := time.NewTimer(b.NextBackOff())
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,88 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package leaderelection
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestRetryingKubernetesElector(t *testing.T) {
+	cfg := NewDefaultConfig()
+	cfg.LeaseName = "lock"
+	cfg.LeaseNamespace = "default"
+	cfg.K8sAPI.RetryOnFailure.Enabled = true
+	cfg.K8sAPI.RetryOnFailure.InitialInterval = 10 * time.Millisecond
+	cfg.K8sAPI.RetryOnFailure.MaxInterval = 20 * time.Millisecond
+
+	var lock sync.Mutex
+	var reported []error
+	started := make(chan struct{})
+	elector := newRetryingKubernetesElector(cfg, "candidate-0", Callbacks{
+		OnStartedLeading: func(context.Context) { close(started) },
+		OnStoppedLeading: func() {},
+		OnBackendError: func(err error) {
+			lock.Lock()
+			defer lock.Unlock()
+			reported = append(reported, err)
+		},
+	})
+	attempts := 0
+	elector.newClient = func(K8sAPIConfig) (kubernetes.Interface, error) {
+		attempts++
+		if attempts < 3 {
+			return nil, errors.New("connection refused")
+		}
+		return fake.NewSimpleClientset(), nil
+	}
+	assert.Equal(t, "candidate-0", elector.Identity())
+	assert.False(t, elector.IsLeader())
+	runTestElector(t, elector)
+
+	select {
+	case <-started:
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "candidate was not elected")
+	}
+	assert.True(t, elector.IsLeader())
+	assert.Equal(t, "candidate-0", elector.GetLeader())
+
+	lock.Lock()
+	defer lock.Unlock()
+	require.Len(t, reported, 3)
+	assert.ErrorContains(t, reported[0], "connection refused")
+	assert.ErrorContains(t, reported[1], "connection refused")
+	assert.NoError(t, reported[2])
+}
+
+func TestRetryingKubernetesElectorCancelled(t *testing.T) {
+	cfg := NewDefaultConfig()
+	cfg.K8sAPI.RetryOnFailure.Enabled = true
+
+	stopped := make(chan struct{})
+	elector := newRetryingKubernetesElector(cfg, "candidate-0", Callbacks{
+		OnStoppedLeading: func() { close(stopped) },
+	})
+	elector.newClient = func(K8sAPIConfig) (kubernetes.Interface, error) {
+		return nil, errors.New("connection refused")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go elector.Run(ctx)
+	cancel()
+
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "election loop did not exit")
+	}
+}
@@ -0,0 +1,154 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package leaderelection
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestKubernetesElectorReleasesLease(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	cfg := NewDefaultConfig()
+
+	started := make(chan struct{})
+	stopped := make(chan struct{})
+	elector, err := newLeaderElector(client, cfg, "candidate-0", Callbacks{
+		OnStartedLeading: func(context.Context) { close(started) },
+		OnStoppedLeading: func() { close(stopped) },
+	})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go elector.Run(ctx)
+
+	select {
+	case <-started:
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "candidate was not elected")
+	}
+	assert.True(t, elector.IsLeader())
+	assert.Equal(t, "candidate-0", elector.GetLeader())
+
+	cancel()
+	<-stopped
+
+	require.Eventually(t, func() bool {
+		lease, err := client.CoordinationV1().Leases(cfg.LeaseNamespace).Get(context.Background(), cfg.LeaseName, metav1.GetOptions{})
+		return err == nil && *lease.Spec.HolderIdentity == ""
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestKubernetesElectorReportsRenewals(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	cfg := NewDefaultConfig()
+	cfg.LeaseDuration = time.Second
+	cfg.RenewDeadline = 500 * time.Millisecond
+	cfg.RetryPeriod = 50 * time.Millisecond
+
+	renewals := make(chan error, 10)
+	leaders := make(chan string, 10)
+	elector, err := newLeaderElector(client, cfg, "candidate-0", Callbacks{
+		OnStartedLeading: func(context.Context) {},
+		OnStoppedLeading: func() {},
+		OnNewLeader: func(identity string) {
+			leaders <- identity
+		},
+		OnRenew: func(_ time.Duration, err error) {
+			select {
+			case renewals <- err:
+			default:
+			}
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "candidate-0", elector.Identity())
+	runTestElector(t, elector)
+
+	select {
+	case leader := <-leaders:
+		assert.Equal(t, "candidate-0", leader)
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "new leader was not reported")
+	}
+	select {
+	case err := <-renewals:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "renewal was not reported")
+	}
+}
+
+func TestLeaseNamespace(t *testing.T) {
+	serviceAccountPath := filepath.Join(t.TempDir(), "namespace")
+	missingPath := filepath.Join(t.TempDir(), "namespace")
+	require.NoError(t, os.WriteFile(serviceAccountPath, []byte("monitoring\n"), 0o600))
+
+	t.Setenv(podNamespaceEnv, "")
+	assert.Equal(t, "default", leaseNamespace("", missingPath, zap.NewNop()))
+
+	t.Setenv(podNamespaceEnv, "observability")
+	assert.Equal(t, "observability", leaseNamespace("", missingPath, zap.NewNop()))
+	assert.Equal(t, "monitoring", leaseNamespace("", serviceAccountPath, zap.NewNop()))
+	assert.Equal(t, "custom", leaseNamespace("custom", serviceAccountPath, zap.NewNop()))
+}
+
+func TestNewRestConfig(t *testing.T) {
+	kubeConfigPath := filepath.Join(t.TempDir(), "config")
+	require.NoError(t, os.WriteFile(kubeConfigPath, []byte(`apiVersion: v1
+kind: Config
+current-context: production
+clusters:
+  - name: production
+    cluster:
+      server: https://production.example.com
+  - name: staging
+    cluster:
+      server: https://staging.example.com
+contexts:
+  - name: production
+    context:
+      cluster: production
+  - name: staging
+    context:
+      cluster: staging
+`), 0o600))
+
+	cfg := NewDefaultConfig().K8sAPI
+	cfg.AuthType = AuthTypeKubeConfig
+	cfg.KubeConfig = kubeConfigPath
+	cfg.Context = "staging"
+	cfg.QPS = 20
+	cfg.Burst = 40
+	cfg.Timeout = 5 * time.Second
+	cfg.UserAgent = "otelcol-leader-election"
+
+	config, err := newRestConfig(cfg)
+	require.NoError(t, err)
+	assert.Equal(t, "https://staging.example.com", config.Host)
+	assert.Equal(t, float32(20), config.QPS)
+	assert.Equal(t, 40, config.Burst)
+	assert.Equal(t, 5*time.Second, config.Timeout)
+	assert.Equal(t, "otelcol-leader-election", config.UserAgent)
+
+	cfg.Context = ""
+	config, err = newRestConfig(cfg)
+	require.NoError(t, err)
+	assert.Equal(t, "https://production.example.com", config.Host)
+
+	t.Setenv("KUBERNETES_SERVICE_HOST", "10.0.0.1")
+	t.Setenv("KUBERNETES_SERVICE_PORT", "443")
+	config, err = newRestConfig(K8sAPIConfig{AuthType: AuthTypeNone})
+	require.NoError(t, err)
+	assert.Equal(t, "https://10.0.0.1:443", config.Host)
+}
@@ -0,0 +1,59 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package leaderelection // import "github.com/skhalash/leaderreceivercreator/internal/leaderelection"
+
+import (
+	"fmt"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// Leadership is the election that gates the work of a leader-gated component.
+type Leadership interface {
+	// IsLeader returns true if this replica is currently the leader.
+	IsLeader() bool
+	// Identity returns the identity of this replica.
+	Identity() string
+	// Subscribe registers callbacks invoked when the leadership changes and returns a function removing them.
+	Subscribe(callbacks Callbacks) (unsubscribe func())
+	// StepDown gives up the leadership for a while, so that another replica can take over.
+	StepDown()
+}
+
+var _ Leadership = (*Candidate)(nil)
+
+// NewLeadership returns the leader_elector extension with the given ID. If no extension is referenced, it
+// creates a Candidate of its own for the component with the given ID, which is returned as the candidate as
+// well. The caller is responsible for starting and shutting down that candidate.
+func NewLeadership(
+	host component.Host,
+	extensionID *component.ID,
+	cfg Config,
+	id component.ID,
+	set component.TelemetrySettings,
+	tracer trace.Tracer,
+) (Leadership, *Candidate, error) {
+	if extensionID != nil {
+		ext, ok := host.GetExtensions()[*extensionID]
+		if !ok {
+			return nil, nil, fmt.Errorf("leader elector %q not found", extensionID.String())
+		}
+		leadership, ok := ext.(Leadership)
+		if !ok {
+			return nil, nil, fmt.Errorf("extension %q is not a leader elector", extensionID.String())
+		}
+		set.Logger.Info("Using leader elector extension...", zap.Stringer("extension", extensionID))
+		return leadership, nil, nil
+	}
+
+	set.Logger.Info("Creating leader elector...", zap.String("backend", cfg.Backend))
+
+	candidate, err := NewCandidate(cfg, id, set, tracer)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create leader elector: %w", err)
+	}
+	return candidate, candidate, nil
+}
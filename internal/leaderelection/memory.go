@@ -0,0 +1,123 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package leaderelection // import "github.com/skhalash/leaderreceivercreator/internal/leaderelection"
+
+import (
+	"context"
+	"sync"
+
+	"go.opentelemetry.io/collector/component"
+)
+
+// memoryLease is a lock shared by the candidates of the same process.
+type memoryLease struct {
+	holder *memoryElector
+	// released is closed when the current holder releases the lease.
+	released chan struct{}
+}
+
+var (
+	memoryLeasesLock sync.Mutex
+	memoryLeases     = map[string]*memoryLease{}
+)
+
+// memoryElector elects the leader among the candidates running in the same process that share the same lease.
+// The lease is held until the Run context of the leader is cancelled.
+type memoryElector struct {
+	key       string
+	identity  string
+	callbacks Callbacks
+	observer  leaderObserver
+}
+
+func newMemoryElector(cfg Config, _ component.TelemetrySettings, callbacks Callbacks) (Elector, error) {
+	identity, err := candidateIdentity(cfg.Identity)
+	if err != nil {
+		return nil, err
+	}
+	return &memoryElector{
+		key:       cfg.LeaseNamespace + "/" + cfg.LeaseName,
+		identity:  identity,
+		callbacks: callbacks,
+	}, nil
+}
+
+func (e *memoryElector) Run(ctx context.Context) {
+	defer e.callbacks.OnStoppedLeading()
+
+	if !e.acquire(ctx) {
+		return
+	}
+	defer e.release()
+
+	leaderCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go e.callbacks.OnStartedLeading(leaderCtx)
+
+	<-ctx.Done()
+}
+
+// acquire blocks until the lease is acquired or ctx is cancelled.
+func (e *memoryElector) acquire(ctx context.Context) bool {
+	for {
+		memoryLeasesLock.Lock()
+		lease, ok := memoryLeases[e.key]
+		if !ok {
+			lease = &memoryLease{}
+			memoryLeases[e.key] = lease
+		}
+		if lease.holder == nil {
+			lease.holder = e
+			lease.released = make(chan struct{})
+			memoryLeasesLock.Unlock()
+			e.observer.observe(e.callbacks, e.identity)
+			return true
+		}
+		released := lease.released
+		holder := lease.holder.identity
+		memoryLeasesLock.Unlock()
+		e.observer.observe(e.callbacks, holder)
+
+		select {
+		case <-ctx.Done():
+			return false
+		case <-released:
+			e.observer.observe(e.callbacks, "")
+		}
+	}
+}
+
+func (e *memoryElector) release() {
+	memoryLeasesLock.Lock()
+	defer memoryLeasesLock.Unlock()
+
+	lease := memoryLeases[e.key]
+	if lease.holder == e {
+		lease.holder = nil
+		close(lease.released)
+		e.observer.observe(e.callbacks, "")
+	}
+}
+
+func (e *memoryElector) IsLeader() bool {
+	memoryLeasesLock.Lock()
+	defer memoryLeasesLock.Unlock()
+
+	lease, ok := memoryLeases[e.key]
+	return ok && lease.holder == e
+}
+
+func (e *memoryElector) GetLeader() string {
+	memoryLeasesLock.Lock()
+	defer memoryLeasesLock.Unlock()
+
+	if lease, ok := memoryLeases[e.key]; ok && lease.holder != nil {
+		return lease.holder.identity
+	}
+	return ""
+}
+
+func (e *memoryElector) Identity() string {
+	return e.identity
+}
@@ -0,0 +1,62 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package leaderelection
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+)
+
+func TestMemoryElectorFailover(t *testing.T) {
+	cfg := NewDefaultConfig()
+	cfg.Backend = BackendMemory
+	cfg.LeaseName = t.Name()
+
+	newCandidate := func(identity string) (Elector, chan struct{}) {
+		cfg := cfg
+		cfg.Identity = identity
+		started := make(chan struct{})
+		elector, err := New(cfg, componenttest.NewNopTelemetrySettings(), Callbacks{
+			OnStartedLeading: func(context.Context) { close(started) },
+			OnStoppedLeading: func() {},
+		})
+		require.NoError(t, err)
+		return elector, started
+	}
+
+	first, firstStarted := newCandidate("first")
+	second, secondStarted := newCandidate("second")
+
+	firstCtx, cancelFirst := context.WithCancel(context.Background())
+	firstDone := make(chan struct{})
+	go func() {
+		first.Run(firstCtx)
+		close(firstDone)
+	}()
+	<-firstStarted
+
+	secondCtx, cancelSecond := context.WithCancel(context.Background())
+	defer cancelSecond()
+	go second.Run(secondCtx)
+
+	assert.True(t, first.IsLeader())
+	assert.False(t, second.IsLeader())
+	assert.Equal(t, "first", second.GetLeader())
+
+	cancelFirst()
+	<-firstDone
+
+	select {
+	case <-secondStarted:
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "second candidate was not elected")
+	}
+	assert.True(t, second.IsLeader())
+	assert.Equal(t, "second", first.GetLeader())
+}
@@ -0,0 +1,309 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package leaderelection // import "github.com/skhalash/leaderreceivercreator/internal/leaderelection"
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/raft"
+	raftboltdb "github.com/hashicorp/raft-boltdb/v2"
+	"go.opentelemetry.io/collector/component"
+	"go.uber.org/zap"
+)
+
+const (
+	raftTransportMaxPool = 3
+	raftTransportTimeout = 10 * time.Second
+	raftRetainSnapshots  = 1
+	raftShutdownTimeout  = 5 * time.Second
+)
+
+// RaftConfig defines the settings of the raft backend.
+type RaftConfig struct {
+	// BindAddress is the address the raft transport listens on.
+	BindAddress string `mapstructure:"bind_address"`
+	// AdvertiseAddress is the address of this candidate as it appears in Peers. Defaults to BindAddress.
+	// It is also used as the identity of the candidate.
+	AdvertiseAddress string `mapstructure:"advertise_address"`
+	// Peers is the static list of the addresses of all candidates, including this one.
+	Peers []string `mapstructure:"peers"`
+	// DataDir is the directory where the raft log and snapshots are stored.
+	DataDir string `mapstructure:"data_dir"`
+}
+
+func (cfg *RaftConfig) advertiseAddress() string {
+	if cfg.AdvertiseAddress != "" {
+		return cfg.AdvertiseAddress
+	}
+	return cfg.BindAddress
+}
+
+func (cfg *RaftConfig) validate() error {
+	if cfg.BindAddress == "" {
+		return errors.New("raft::bind_address must not be empty")
+	}
+	if cfg.DataDir == "" {
+		return errors.New("raft::data_dir must not be empty")
+	}
+	if len(cfg.Peers) == 0 {
+		return errors.New("raft::peers must not be empty")
+	}
+
+	advertiseAddress := cfg.advertiseAddress()
+	if _, _, err := net.SplitHostPort(advertiseAddress); err != nil {
+		return fmt.Errorf("invalid raft::advertise_address %q: %w", advertiseAddress, err)
+	}
+	seen := make(map[string]bool, len(cfg.Peers))
+	for _, peer := range cfg.Peers {
+		if seen[peer] {
+			return fmt.Errorf("duplicate raft::peers entry %q", peer)
+		}
+		seen[peer] = true
+	}
+	if !seen[advertiseAddress] {
+		return fmt.Errorf("raft::peers must contain the address of this candidate %q", advertiseAddress)
+	}
+	return nil
+}
+
+// raftElector elects the leader using an embedded raft cluster formed by a static list of peers.
+// The raft node is started on the first call of Run and keeps running while the candidate campaigns again,
+// it is stopped when the Run context is cancelled.
+type raftElector struct {
+	cfg             RaftConfig
+	retryPeriod     time.Duration
+	releaseOnCancel bool
+	callbacks       Callbacks
+	logger          *zap.Logger
+	observer        leaderObserver
+
+	lock         sync.Mutex
+	node         *raft.Raft
+	raftObserver *raft.Observer
+	observations chan raft.Observation
+	closers      []io.Closer
+}
+
+func newRaftElector(cfg Config, set component.TelemetrySettings, callbacks Callbacks) (Elector, error) {
+	return &raftElector{
+		cfg:             cfg.Raft,
+		retryPeriod:     cfg.RetryPeriod,
+		releaseOnCancel: cfg.ReleaseOnCancel,
+		callbacks:       callbacks,
+		logger:          set.Logger,
+	}, nil
+}
+
+func (e *raftElector) Run(ctx context.Context) {
+	defer e.callbacks.OnStoppedLeading()
+
+	node, err := e.start()
+	if err != nil {
+		e.logger.Error("Failed to start raft node", zap.Error(err))
+		// Wait before returning to not restart the node in a tight loop.
+		select {
+		case <-ctx.Done():
+		case <-time.After(e.retryPeriod):
+		}
+		return
+	}
+
+	// LeaderCh keeps only the latest leadership transition, so the current state is checked on every notification.
+	leaderCh := node.LeaderCh()
+	for node.State() != raft.Leader {
+		select {
+		case <-ctx.Done():
+			e.stop()
+			return
+		case <-leaderCh:
+		}
+	}
+
+	leaderCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go e.callbacks.OnStartedLeading(leaderCtx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			e.stop()
+			return
+		case <-leaderCh:
+			if node.State() != raft.Leader {
+				return
+			}
+		}
+	}
+}
+
+// start starts the raft node unless it is already running.
+func (e *raftElector) start() (*raft.Raft, error) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	if e.node != nil {
+		return e.node, nil
+	}
+
+	if err := os.MkdirAll(e.cfg.DataDir, 0o700); err != nil {
+		return nil, err
+	}
+
+	logWriter := zap.NewStdLog(e.logger).Writer()
+	raftConfig := raft.DefaultConfig()
+	raftConfig.LocalID = raft.ServerID(e.cfg.advertiseAddress())
+	raftConfig.Logger = hclog.New(&hclog.LoggerOptions{
+		Name:   "raft",
+		Level:  hclog.Warn,
+		Output: logWriter,
+	})
+	store, err := raftboltdb.NewBoltStore(filepath.Join(e.cfg.DataDir, "raft.db"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create raft store: %w", err)
+	}
+	snapshots, err := raft.NewFileSnapshotStore(e.cfg.DataDir, raftRetainSnapshots, logWriter)
+	if err != nil {
+		_ = store.Close()
+		return nil, fmt.Errorf("failed to create raft snapshot store: %w", err)
+	}
+	advertiseAddr, err := net.ResolveTCPAddr("tcp", e.cfg.advertiseAddress())
+	if err != nil {
+		_ = store.Close()
+		return nil, err
+	}
+	transport, err := raft.NewTCPTransport(e.cfg.BindAddress, advertiseAddr, raftTransportMaxPool, raftTransportTimeout, logWriter)
+	if err != nil {
+		_ = store.Close()
+		return nil, fmt.Errorf("failed to create raft transport: %w", err)
+	}
+
+	node, err := raft.NewRaft(raftConfig, raftFSM{}, store, store, snapshots, transport)
+	if err != nil {
+		_ = transport.Close()
+		_ = store.Close()
+		return nil, fmt.Errorf("failed to create raft node: %w", err)
+	}
+
+	// All peers bootstrap the cluster with the same configuration, which is a no-op if it already has state.
+	servers := make([]raft.Server, 0, len(e.cfg.Peers))
+	for _, peer := range e.cfg.Peers {
+		servers = append(servers, raft.Server{ID: raft.ServerID(peer), Address: raft.ServerAddress(peer)})
+	}
+	if err := node.BootstrapCluster(raft.Configuration{Servers: servers}).Error(); err != nil && !errors.Is(err, raft.ErrCantBootstrap) {
+		e.logger.Warn("Failed to bootstrap raft cluster", zap.Error(err))
+	}
+
+	// Leader changes are reported by the raft observer, which is deregistered and closed on stop.
+	e.observations = make(chan raft.Observation, 1)
+	e.raftObserver = raft.NewObserver(e.observations, false, func(o *raft.Observation) bool {
+		_, ok := o.Data.(raft.LeaderObservation)
+		return ok
+	})
+	node.RegisterObserver(e.raftObserver)
+	go e.observeLeader(e.observations)
+
+	e.node = node
+	e.closers = []io.Closer{transport, store}
+	return node, nil
+}
+
+// observeLeader reports the leader changes until the observations channel is closed.
+func (e *raftElector) observeLeader(observations <-chan raft.Observation) {
+	for observation := range observations {
+		leader := observation.Data.(raft.LeaderObservation)
+		e.observer.observe(e.callbacks, string(leader.LeaderID))
+	}
+}
+
+// stop transfers the leadership if configured and stops the raft node.
+func (e *raftElector) stop() {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	if e.node == nil {
+		return
+	}
+
+	if e.releaseOnCancel && e.node.State() == raft.Leader {
+		if err := e.node.LeadershipTransfer().Error(); err != nil {
+			e.logger.Warn("Failed to transfer raft leadership", zap.Error(err))
+		}
+	}
+
+	future := e.node.Shutdown()
+	done := make(chan error, 1)
+	go func() {
+		done <- future.Error()
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			e.logger.Warn("Failed to shut down raft node", zap.Error(err))
+		}
+	case <-time.After(raftShutdownTimeout):
+		e.logger.Warn("Timed out shutting down raft node")
+	}
+
+	e.node.DeregisterObserver(e.raftObserver)
+	close(e.observations)
+	for _, closer := range e.closers {
+		_ = closer.Close()
+	}
+	e.node = nil
+	e.raftObserver = nil
+	e.observations = nil
+	e.closers = nil
+}
+
+func (e *raftElector) IsLeader() bool {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	return e.node != nil && e.node.State() == raft.Leader
+}
+
+func (e *raftElector) GetLeader() string {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	if e.node == nil {
+		return ""
+	}
+	_, id := e.node.LeaderWithID()
+	return string(id)
+}
+
+func (e *raftElector) Identity() string {
+	return e.cfg.advertiseAddress()
+}
+
+// raftFSM is a no-op state machine, the raft cluster is used only to elect the leader.
+type raftFSM struct{}
+
+func (raftFSM) Apply(*raft.Log) any {
+	return nil
+}
+
+func (raftFSM) Snapshot() (raft.FSMSnapshot, error) {
+	return raftFSMSnapshot{}, nil
+}
+
+func (raftFSM) Restore(snapshot io.ReadCloser) error {
+	return snapshot.Close()
+}
+
+type raftFSMSnapshot struct{}
+
+func (raftFSMSnapshot) Persist(sink raft.SnapshotSink) error {
+	return sink.Close()
+}
+
+func (raftFSMSnapshot) Release() {}
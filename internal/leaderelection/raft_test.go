@@ -0,0 +1,140 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package leaderelection
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+)
+
+// freeAddress returns a loopback address with a port that is free at the time of the call.
+func freeAddress(t *testing.T) string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+	return l.Addr().String()
+}
+
+func TestRaftElectorThreeNodes(t *testing.T) {
+	peers := []string{freeAddress(t), freeAddress(t), freeAddress(t)}
+
+	electors := make([]Elector, len(peers))
+	stops := make([]func(), len(peers))
+	for i, peer := range peers {
+		cfg := NewDefaultConfig()
+		cfg.Backend = BackendRaft
+		cfg.Raft = RaftConfig{
+			BindAddress: peer,
+			Peers:       peers,
+			DataDir:     t.TempDir(),
+		}
+		require.NoError(t, cfg.Validate())
+
+		elector, err := New(cfg, componenttest.NewNopTelemetrySettings(), Callbacks{
+			OnStartedLeading: func(context.Context) {},
+			OnStoppedLeading: func() {},
+		})
+		require.NoError(t, err)
+		electors[i] = elector
+		stops[i] = runTestElector(t, elector)
+	}
+
+	leaderIndex := func(candidates []int) int {
+		leader := -1
+		for _, i := range candidates {
+			if electors[i].IsLeader() {
+				if leader != -1 {
+					return -1
+				}
+				leader = i
+			}
+		}
+		return leader
+	}
+
+	var leader int
+	require.Eventually(t, func() bool {
+		leader = leaderIndex([]int{0, 1, 2})
+		return leader != -1
+	}, 10*time.Second, 50*time.Millisecond)
+	for _, elector := range electors {
+		assert.Eventually(t, func() bool {
+			return elector.GetLeader() == peers[leader]
+		}, 5*time.Second, 50*time.Millisecond)
+	}
+
+	stops[leader]()
+	assert.False(t, electors[leader].IsLeader())
+
+	var remaining []int
+	for i := range peers {
+		if i != leader {
+			remaining = append(remaining, i)
+		}
+	}
+	require.Eventually(t, func() bool {
+		return leaderIndex(remaining) != -1
+	}, 10*time.Second, 50*time.Millisecond)
+}
+
+func TestRaftConfigValidate(t *testing.T) {
+	tests := []struct {
+		name        string
+		cfg         RaftConfig
+		expectedErr string
+	}{
+		{
+			name: "valid",
+			cfg: RaftConfig{
+				BindAddress:      "0.0.0.0:7000",
+				AdvertiseAddress: "collector-0.collector:7000",
+				Peers:            []string{"collector-0.collector:7000", "collector-1.collector:7000"},
+				DataDir:          "/var/lib/otelcol/raft",
+			},
+		},
+		{
+			name: "missing own address",
+			cfg: RaftConfig{
+				BindAddress: "127.0.0.1:7000",
+				Peers:       []string{"127.0.0.1:7001"},
+				DataDir:     "/var/lib/otelcol/raft",
+			},
+			expectedErr: `raft::peers must contain the address of this candidate "127.0.0.1:7000"`,
+		},
+		{
+			name: "duplicate peer",
+			cfg: RaftConfig{
+				BindAddress: "127.0.0.1:7000",
+				Peers:       []string{"127.0.0.1:7000", "127.0.0.1:7000"},
+				DataDir:     "/var/lib/otelcol/raft",
+			},
+			expectedErr: `duplicate raft::peers entry "127.0.0.1:7000"`,
+		},
+		{
+			name: "missing data dir",
+			cfg: RaftConfig{
+				BindAddress: "127.0.0.1:7000",
+				Peers:       []string{"127.0.0.1:7000"},
+			},
+			expectedErr: "raft::data_dir must not be empty",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cfg.validate()
+			if tt.expectedErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tt.expectedErr)
+		})
+	}
+}
@@ -0,0 +1,310 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package leaderelection // import "github.com/skhalash/leaderreceivercreator/internal/leaderelection"
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/configopaque"
+	"go.opentelemetry.io/collector/config/configtls"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultRedisKeyPrefix = "leader_receiver_creator"
+	redisReleaseTimeout   = 5 * time.Second
+)
+
+var (
+	// redisAcquireScript sets the lock key if it does not exist and increments the fencing token of the lock
+	// in the same transaction, so that every acquisition gets a strictly greater token.
+	redisAcquireScript = redis.NewScript(`
+if redis.call("SET", KEYS[1], ARGV[1], "NX", "PX", ARGV[2]) then
+	return redis.call("INCR", KEYS[2])
+end
+return 0
+`)
+	// redisRenewScript extends the expiration of the lock key if it is still held by the candidate.
+	redisRenewScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0
+`)
+	// redisReleaseScript deletes the lock key if it is still held by the candidate.
+	redisReleaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+)
+
+// RedisConfig defines the settings of the redis backend.
+type RedisConfig struct {
+	// Endpoint is the address of the redis server in the host:port format.
+	Endpoint string `mapstructure:"endpoint"`
+	// Username is the redis user name used for authentication.
+	Username string `mapstructure:"username"`
+	// Password is the redis password used for authentication.
+	Password configopaque.String `mapstructure:"password"`
+	// DB is the redis database to select.
+	DB int `mapstructure:"db"`
+	// TLS configures the connection to redis. TLS is disabled by default.
+	TLS configtls.ClientConfig `mapstructure:"tls"`
+	// KeyPrefix is the prefix of the lock key, the lease namespace and name are appended to it.
+	KeyPrefix string `mapstructure:"key_prefix"`
+}
+
+func (cfg *RedisConfig) validate() error {
+	if cfg.Endpoint == "" {
+		return errors.New("redis::endpoint must not be empty")
+	}
+	if _, _, err := splitHostPort(cfg.Endpoint); err != nil {
+		return fmt.Errorf("invalid redis::endpoint %q: %w", cfg.Endpoint, err)
+	}
+	if cfg.DB < 0 {
+		return fmt.Errorf("redis::db (%d) must not be negative", cfg.DB)
+	}
+	if cfg.KeyPrefix == "" {
+		return errors.New("redis::key_prefix must not be empty")
+	}
+	return nil
+}
+
+type fencingTokenKey struct{}
+
+// FencingTokenFromContext returns the fencing token of the leadership the context passed to
+// Callbacks.OnStartedLeading belongs to. The token grows with every acquisition of the lock, so the leader-gated
+// work can pass it along with its writes for the target to reject the writes of a former leader. Only the redis
+// backend provides fencing tokens.
+func FencingTokenFromContext(ctx context.Context) (int64, bool) {
+	token, ok := ctx.Value(fencingTokenKey{}).(int64)
+	return token, ok
+}
+
+// redisElector elects the leader using a lock key in redis. The lock is acquired with SET NX PX, the key holding
+// the identity of the leader and expiring after lease_duration unless it is renewed. Renewal and release are done
+// with scripts that check the holder, so a candidate never extends or deletes a lock held by another one.
+type redisElector struct {
+	cfg             RedisConfig
+	key             string
+	fencingKey      string
+	identity        string
+	leaseDuration   time.Duration
+	renewDeadline   time.Duration
+	retryPeriod     time.Duration
+	releaseOnCancel bool
+	callbacks       Callbacks
+	logger          *zap.Logger
+	observer        leaderObserver
+
+	lock     sync.Mutex
+	client   *redis.Client
+	leader   string
+	isLeader bool
+}
+
+func newRedisElector(cfg Config, set component.TelemetrySettings, callbacks Callbacks) (Elector, error) {
+	identity, err := candidateIdentity(cfg.Identity)
+	if err != nil {
+		return nil, err
+	}
+	key := fmt.Sprintf("%s:%s/%s", cfg.Redis.KeyPrefix, cfg.LeaseNamespace, cfg.LeaseName)
+	return &redisElector{
+		cfg:             cfg.Redis,
+		key:             key,
+		fencingKey:      key + ":fencing",
+		identity:        identity,
+		leaseDuration:   cfg.LeaseDuration,
+		renewDeadline:   cfg.RenewDeadline,
+		retryPeriod:     cfg.RetryPeriod,
+		releaseOnCancel: cfg.ReleaseOnCancel,
+		callbacks:       callbacks,
+		logger:          set.Logger,
+	}, nil
+}
+
+func (e *redisElector) Run(ctx context.Context) {
+	defer e.callbacks.OnStoppedLeading()
+	defer func() {
+		if ctx.Err() != nil {
+			e.closeClient()
+		}
+	}()
+
+	client, err := e.getClient()
+	if err != nil {
+		e.logger.Error("Failed to create redis client", zap.Error(err))
+		select {
+		case <-ctx.Done():
+		case <-time.After(e.retryPeriod):
+		}
+		return
+	}
+
+	token, ok := e.acquire(ctx, client)
+	if !ok {
+		return
+	}
+
+	e.setLeader(e.identity, true)
+	defer e.setLeader("", false)
+	e.logger.Info("Elected as leader in redis", zap.String("key", e.key), zap.Int64("fencing_token", token))
+
+	leaderCtx, cancel := context.WithCancel(context.WithValue(ctx, fencingTokenKey{}, token))
+	defer cancel()
+	go e.callbacks.OnStartedLeading(leaderCtx)
+
+	e.renew(ctx, client)
+
+	if ctx.Err() != nil && e.releaseOnCancel {
+		releaseCtx, cancelRelease := context.WithTimeout(context.Background(), redisReleaseTimeout)
+		defer cancelRelease()
+		if err := redisReleaseScript.Run(releaseCtx, client, []string{e.key}, e.identity).Err(); err != nil {
+			e.logger.Warn("Failed to release redis lock", zap.Error(err))
+		}
+	}
+}
+
+// acquire tries to acquire the lock every retry_period until it succeeds or ctx is cancelled.
+// It returns the fencing token of the acquisition.
+func (e *redisElector) acquire(ctx context.Context, client *redis.Client) (int64, bool) {
+	ticker := time.NewTicker(e.retryPeriod)
+	defer ticker.Stop()
+
+	for {
+		token, err := redisAcquireScript.Run(ctx, client, []string{e.key, e.fencingKey},
+			e.identity, e.leaseDuration.Milliseconds()).Int64()
+		switch {
+		case err != nil:
+			if ctx.Err() == nil {
+				e.logger.Warn("Failed to acquire redis lock", zap.Error(err))
+			}
+		case token > 0:
+			return token, true
+		default:
+			e.observeLeader(ctx, client)
+		}
+
+		select {
+		case <-ctx.Done():
+			return 0, false
+		case <-ticker.C:
+		}
+	}
+}
+
+// renew renews the lock every retry_period and returns when ctx is cancelled or the lock is lost.
+// The lock is considered lost if it is held by another candidate or could not be renewed within renew_deadline.
+func (e *redisElector) renew(ctx context.Context, client *redis.Client) {
+	ticker := time.NewTicker(e.retryPeriod)
+	defer ticker.Stop()
+
+	lastRenew := time.Now()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		start := time.Now()
+		renewed, err := redisRenewScript.Run(ctx, client, []string{e.key},
+			e.identity, e.leaseDuration.Milliseconds()).Int64()
+		if ctx.Err() == nil {
+			e.callbacks.renewed(time.Since(start), err)
+		}
+		switch {
+		case err != nil:
+			if ctx.Err() != nil {
+				return
+			}
+			e.logger.Warn("Failed to renew redis lock", zap.Error(err))
+			if time.Since(lastRenew) > e.renewDeadline {
+				e.logger.Error("Failed to renew redis lock within the renew deadline, stepping down")
+				return
+			}
+		case renewed == 0:
+			e.logger.Warn("Redis lock is no longer held by this candidate, stepping down")
+			return
+		default:
+			lastRenew = time.Now()
+		}
+	}
+}
+
+// observeLeader records the current holder of the lock.
+func (e *redisElector) observeLeader(ctx context.Context, client *redis.Client) {
+	leader, err := client.Get(ctx, e.key).Result()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return
+	}
+	e.setLeader(leader, false)
+}
+
+func (e *redisElector) getClient() (*redis.Client, error) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	if e.client != nil {
+		return e.client, nil
+	}
+
+	tlsConfig, err := e.cfg.TLS.LoadTLSConfig(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("failed to load redis TLS config: %w", err)
+	}
+	e.client = redis.NewClient(&redis.Options{
+		Addr:      e.cfg.Endpoint,
+		Username:  e.cfg.Username,
+		Password:  string(e.cfg.Password),
+		DB:        e.cfg.DB,
+		TLSConfig: tlsConfig,
+	})
+	return e.client, nil
+}
+
+func (e *redisElector) closeClient() {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	if e.client == nil {
+		return
+	}
+	if err := e.client.Close(); err != nil {
+		e.logger.Warn("Failed to close redis client", zap.Error(err))
+	}
+	e.client = nil
+}
+
+func (e *redisElector) setLeader(leader string, isLeader bool) {
+	e.lock.Lock()
+	e.leader = leader
+	e.isLeader = isLeader
+	e.lock.Unlock()
+	e.observer.observe(e.callbacks, leader)
+}
+
+func (e *redisElector) IsLeader() bool {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	return e.isLeader
+}
+
+func (e *redisElector) GetLeader() string {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	return e.leader
+}
+
+func (e *redisElector) Identity() string {
+	return e.identity
+}
@@ -0,0 +1,189 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package leaderelection
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+func newTestRedisConfig(endpoint, identity string) Config {
+	cfg := NewDefaultConfig()
+	cfg.Backend = BackendRedis
+	cfg.LeaseName = "lock"
+	cfg.LeaseNamespace = "default"
+	cfg.Identity = identity
+	cfg.LeaseDuration = time.Second
+	cfg.RenewDeadline = 500 * time.Millisecond
+	cfg.RetryPeriod = 50 * time.Millisecond
+	cfg.Redis.Endpoint = endpoint
+	return cfg
+}
+
+func newTestRedisElector(t *testing.T, endpoint, identity string) Elector {
+	cfg := newTestRedisConfig(endpoint, identity)
+	require.NoError(t, cfg.Validate())
+
+	elector, err := New(cfg, componenttest.NewNopTelemetrySettings(), Callbacks{
+		OnStartedLeading: func(context.Context) {},
+		OnStoppedLeading: func() {},
+	})
+	require.NoError(t, err)
+	return elector
+}
+
+func TestRedisElectorFailover(t *testing.T) {
+	server := miniredis.RunT(t)
+
+	fencingKey := "leader_receiver_creator:default/lock:fencing"
+	first := newTestRedisElector(t, server.Addr(), "first")
+	stopFirst := runTestElector(t, first)
+	require.Eventually(t, first.IsLeader, 5*time.Second, 20*time.Millisecond)
+	firstToken, err := server.Get(fencingKey)
+	require.NoError(t, err)
+	assert.Equal(t, "1", firstToken)
+
+	second := newTestRedisElector(t, server.Addr(), "second")
+	runTestElector(t, second)
+	require.Eventually(t, func() bool {
+		return second.GetLeader() == "first"
+	}, 5*time.Second, 20*time.Millisecond)
+	assert.False(t, second.IsLeader())
+
+	// The first candidate releases the lock on cancel, so the second one takes over without waiting for it to expire.
+	stopFirst()
+	assert.False(t, first.IsLeader())
+	require.Eventually(t, second.IsLeader, 500*time.Millisecond, 20*time.Millisecond)
+	assert.Equal(t, "second", second.GetLeader())
+	// Every acquisition increments the fencing token.
+	secondToken, err := server.Get(fencingKey)
+	require.NoError(t, err)
+	assert.Equal(t, "2", secondToken)
+}
+
+func TestRedisElectorKeyPerComponent(t *testing.T) {
+	server := miniredis.RunT(t)
+	cfg := newTestRedisConfig(server.Addr(), "first")
+	cfg.LeaseName = ""
+
+	// The candidates of two components lead at once, because their lock keys are derived from their IDs.
+	for _, name := range []string{"a", "b"} {
+		candidate, err := NewCandidate(cfg, component.MustNewIDWithName("leader_receiver_creator", name),
+			componenttest.NewNopTelemetrySettings(), noop.NewTracerProvider().Tracer(""))
+		require.NoError(t, err)
+		candidate.Start()
+		defer func() {
+			require.NoError(t, candidate.Shutdown(context.Background()))
+		}()
+		assert.Eventually(t, candidate.IsLeader, 5*time.Second, 20*time.Millisecond)
+	}
+	assert.True(t, server.Exists("leader_receiver_creator:default/leader-receiver-creator-a-9e4489f7"))
+	assert.True(t, server.Exists("leader_receiver_creator:default/leader-receiver-creator-b-9f448b8a"))
+}
+
+func TestRedisElectorStepsDownWhenLockIsLost(t *testing.T) {
+	server := miniredis.RunT(t)
+
+	elector := newTestRedisElector(t, server.Addr(), "first")
+	runTestElector(t, elector)
+	require.Eventually(t, elector.IsLeader, 5*time.Second, 20*time.Millisecond)
+
+	// Another candidate takes over the lock, e.g. after it expired while the leader was paused.
+	lockKey := "leader_receiver_creator:default/lock"
+	server.Set(lockKey, "other")
+	require.Eventually(t, func() bool {
+		return !elector.IsLeader() && elector.GetLeader() == "other"
+	}, 5*time.Second, 20*time.Millisecond)
+}
+
+func TestRedisConfigValidate(t *testing.T) {
+	tests := []struct {
+		name        string
+		cfg         RedisConfig
+		expectedErr string
+	}{
+		{
+			name: "valid",
+			cfg:  RedisConfig{Endpoint: "localhost:6379", KeyPrefix: "prefix"},
+		},
+		{
+			name:        "missing endpoint",
+			cfg:         RedisConfig{KeyPrefix: "prefix"},
+			expectedErr: "redis::endpoint must not be empty",
+		},
+		{
+			name:        "invalid endpoint",
+			cfg:         RedisConfig{Endpoint: "localhost", KeyPrefix: "prefix"},
+			expectedErr: `invalid redis::endpoint "localhost": address localhost: missing port in address`,
+		},
+		{
+			name:        "negative db",
+			cfg:         RedisConfig{Endpoint: "localhost:6379", DB: -1, KeyPrefix: "prefix"},
+			expectedErr: "redis::db (-1) must not be negative",
+		},
+		{
+			name:        "missing key prefix",
+			cfg:         RedisConfig{Endpoint: "localhost:6379"},
+			expectedErr: "redis::key_prefix must not be empty",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cfg.validate()
+			if tt.expectedErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tt.expectedErr)
+		})
+	}
+}
+
+func TestRedisFencingTokenPerLeadership(t *testing.T) {
+	server := miniredis.RunT(t)
+
+	// The subscribers read the fencing token of the leadership from the context.
+	newCandidate := func(identity string, tokens chan<- int64) *Candidate {
+		candidate, err := NewCandidate(newTestRedisConfig(server.Addr(), identity), component.MustNewID("leader_receiver_creator"),
+			componenttest.NewNopTelemetrySettings(), noop.NewTracerProvider().Tracer(""))
+		require.NoError(t, err)
+		candidate.Subscribe(Callbacks{
+			OnStartedLeading: func(ctx context.Context) {
+				token, ok := FencingTokenFromContext(ctx)
+				assert.True(t, ok)
+				tokens <- token
+			},
+		})
+		candidate.Start()
+		return candidate
+	}
+
+	firstTokens := make(chan int64, 1)
+	first := newCandidate("first", firstTokens)
+	firstToken := <-firstTokens
+
+	secondTokens := make(chan int64, 1)
+	second := newCandidate("second", secondTokens)
+	defer func() {
+		require.NoError(t, second.Shutdown(context.Background()))
+	}()
+
+	// The token of the new leader is greater, so the writes of the former one can be rejected.
+	require.NoError(t, first.Shutdown(context.Background()))
+	select {
+	case secondToken := <-secondTokens:
+		assert.Greater(t, secondToken, firstToken)
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "second candidate was not elected")
+	}
+}
@@ -3,9 +3,16 @@
 package metadata
 
 import (
-	"go.opentelemetry.io/collector/component"
+	"context"
+	"errors"
+
+	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
 	"go.opentelemetry.io/otel/trace"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/configtelemetry"
 )
 
 func Meter(settings component.TelemetrySettings) metric.Meter {
@@ -15,3 +22,131 @@ func Meter(settings component.TelemetrySettings) metric.Meter {
 func Tracer(settings component.TelemetrySettings) trace.Tracer {
 	return settings.TracerProvider.Tracer("otelcol/leaderreceivercreator")
 }
+
+// TelemetryBuilder provides an interface for components to report telemetry
+// as defined in metadata and user config.
+type TelemetryBuilder struct {
+	LeaderReceiverCreatorFailoverGapDuration             metric.Float64Histogram
+	LeaderReceiverCreatorIsLeader                        metric.Int64ObservableGauge
+	observeLeaderReceiverCreatorIsLeader                 func() int64
+	LeaderReceiverCreatorLeadershipTransitions           metric.Int64Counter
+	LeaderReceiverCreatorLeaseRenewLatency               metric.Float64Histogram
+	LeaderReceiverCreatorRunningWithoutLeadership        metric.Int64ObservableGauge
+	observeLeaderReceiverCreatorRunningWithoutLeadership func() int64
+	LeaderReceiverCreatorSubreceiverRunning              metric.Int64ObservableGauge
+	observeLeaderReceiverCreatorSubreceiverRunning       func() int64
+	LeaderReceiverCreatorSubreceiverStartFailures        metric.Int64Counter
+	level                                                configtelemetry.Level
+	attributeSet                                         attribute.Set
+}
+
+// telemetryBuilderOption applies changes to default builder.
+type telemetryBuilderOption func(*TelemetryBuilder)
+
+// WithLevel sets the current telemetry level for the component.
+func WithLevel(lvl configtelemetry.Level) telemetryBuilderOption {
+	return func(builder *TelemetryBuilder) {
+		builder.level = lvl
+	}
+}
+
+// WithAttributeSet applies a set of attributes for asynchronous instruments.
+func WithAttributeSet(set attribute.Set) telemetryBuilderOption {
+	return func(builder *TelemetryBuilder) {
+		builder.attributeSet = set
+	}
+}
+
+// WithLeaderReceiverCreatorIsLeaderCallback sets callback for observable LeaderReceiverCreatorIsLeader metric.
+func WithLeaderReceiverCreatorIsLeaderCallback(cb func() int64) telemetryBuilderOption {
+	return func(builder *TelemetryBuilder) {
+		builder.observeLeaderReceiverCreatorIsLeader = cb
+	}
+}
+
+// WithLeaderReceiverCreatorRunningWithoutLeadershipCallback sets callback for observable LeaderReceiverCreatorRunningWithoutLeadership metric.
+func WithLeaderReceiverCreatorRunningWithoutLeadershipCallback(cb func() int64) telemetryBuilderOption {
+	return func(builder *TelemetryBuilder) {
+		builder.observeLeaderReceiverCreatorRunningWithoutLeadership = cb
+	}
+}
+
+// WithLeaderReceiverCreatorSubreceiverRunningCallback sets callback for observable LeaderReceiverCreatorSubreceiverRunning metric.
+func WithLeaderReceiverCreatorSubreceiverRunningCallback(cb func() int64) telemetryBuilderOption {
+	return func(builder *TelemetryBuilder) {
+		builder.observeLeaderReceiverCreatorSubreceiverRunning = cb
+	}
+}
+
+// NewTelemetryBuilder provides a struct with methods to update all internal telemetry
+// for a component
+func NewTelemetryBuilder(settings component.TelemetrySettings, options ...telemetryBuilderOption) (*TelemetryBuilder, error) {
+	builder := TelemetryBuilder{level: configtelemetry.LevelBasic}
+	for _, op := range options {
+		op(&builder)
+	}
+	var (
+		err, errs error
+		meter     metric.Meter
+	)
+	if builder.level >= configtelemetry.LevelBasic {
+		meter = Meter(settings)
+	} else {
+		meter = noop.Meter{}
+	}
+	builder.LeaderReceiverCreatorFailoverGapDuration, err = meter.Float64Histogram(
+		"leader_receiver_creator_failover_gap_duration",
+		metric.WithDescription("Time the leadership was observed vacant before this replica took it over."),
+		metric.WithUnit("s"), metric.WithExplicitBucketBoundaries([]float64{0.1, 0.5, 1, 2.5, 5, 10, 15, 30, 60, 120}...),
+	)
+	errs = errors.Join(errs, err)
+	builder.LeaderReceiverCreatorIsLeader, err = meter.Int64ObservableGauge(
+		"leader_receiver_creator_is_leader",
+		metric.WithDescription("Whether this replica is the leader (1) or not (0)."),
+		metric.WithUnit("1"),
+		metric.WithInt64Callback(func(_ context.Context, o metric.Int64Observer) error {
+			o.Observe(builder.observeLeaderReceiverCreatorIsLeader(), metric.WithAttributeSet(builder.attributeSet))
+			return nil
+		}),
+	)
+	errs = errors.Join(errs, err)
+	builder.LeaderReceiverCreatorLeadershipTransitions, err = meter.Int64Counter(
+		"leader_receiver_creator_leadership_transitions",
+		metric.WithDescription("Number of times this replica acquired or lost the leadership."),
+		metric.WithUnit("{transitions}"),
+	)
+	errs = errors.Join(errs, err)
+	builder.LeaderReceiverCreatorLeaseRenewLatency, err = meter.Float64Histogram(
+		"leader_receiver_creator_lease_renew_latency",
+		metric.WithDescription("Latency of the lease renewals made by the leader."),
+		metric.WithUnit("s"), metric.WithExplicitBucketBoundaries([]float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}...),
+	)
+	errs = errors.Join(errs, err)
+	builder.LeaderReceiverCreatorRunningWithoutLeadership, err = meter.Int64ObservableGauge(
+		"leader_receiver_creator_running_without_leadership",
+		metric.WithDescription("Whether the subreceivers run on this replica without the leadership (1) or not (0), as allowed by the on_renew_failure policy."),
+		metric.WithUnit("1"),
+		metric.WithInt64Callback(func(_ context.Context, o metric.Int64Observer) error {
+			o.Observe(builder.observeLeaderReceiverCreatorRunningWithoutLeadership(), metric.WithAttributeSet(builder.attributeSet))
+			return nil
+		}),
+	)
+	errs = errors.Join(errs, err)
+	builder.LeaderReceiverCreatorSubreceiverRunning, err = meter.Int64ObservableGauge(
+		"leader_receiver_creator_subreceiver_running",
+		metric.WithDescription("Number of subreceivers running on this replica."),
+		metric.WithUnit("{receivers}"),
+		metric.WithInt64Callback(func(_ context.Context, o metric.Int64Observer) error {
+			o.Observe(builder.observeLeaderReceiverCreatorSubreceiverRunning(), metric.WithAttributeSet(builder.attributeSet))
+			return nil
+		}),
+	)
+	errs = errors.Join(errs, err)
+	builder.LeaderReceiverCreatorSubreceiverStartFailures, err = meter.Int64Counter(
+		"leader_receiver_creator_subreceiver_start_failures",
+		metric.WithDescription("Number of failed attempts to start the subreceivers, including the restarts."),
+		metric.WithUnit("{failures}"),
+	)
+	errs = errors.Join(errs, err)
+	return &builder, errs
+}
@@ -0,0 +1,18 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package leaderelectorextension // import "github.com/skhalash/leaderreceivercreator/leaderelectorextension"
+
+import (
+	"go.opentelemetry.io/collector/component"
+
+	"github.com/skhalash/leaderreceivercreator/internal/leaderelection"
+)
+
+var _ component.Config = (*Config)(nil)
+
+// Config defines configuration for the leader_elector extension. It takes the same settings as the
+// leader_election section of leader_receiver_creator.
+type Config struct {
+	leaderelection.Config `mapstructure:",squash"`
+}
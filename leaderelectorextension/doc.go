@@ -0,0 +1,8 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:generate mdatagen metadata.yaml
+
+// Package leaderelectorextension provides an extension that runs a leader election shared by the
+// leader-gated components of a collector.
+package leaderelectorextension // import "github.com/skhalash/leaderreceivercreator/leaderelectorextension"
@@ -0,0 +1,129 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package leaderelectorextension // import "github.com/skhalash/leaderreceivercreator/leaderelectorextension"
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/extension"
+	"go.uber.org/zap"
+
+	"github.com/skhalash/leaderreceivercreator/internal/leaderelection"
+	"github.com/skhalash/leaderreceivercreator/leaderelectorextension/internal/metadata"
+)
+
+// Callbacks are invoked when the leadership of the collector changes.
+type Callbacks = leaderelection.Callbacks
+
+// FencingTokenFromContext returns the fencing token of the leadership the context passed to
+// Callbacks.OnStartedLeading belongs to. Only the redis backend provides fencing tokens.
+func FencingTokenFromContext(ctx context.Context) (int64, bool) {
+	return leaderelection.FencingTokenFromContext(ctx)
+}
+
+// LeaderElector is implemented by the leader_elector extension. The leader-gated components look it up
+// among the extensions of the host to follow the leadership of the collector.
+type LeaderElector interface {
+	extension.Extension
+	// IsLeader returns true if the collector is currently the leader.
+	IsLeader() bool
+	// GetLeader returns the identity of the last observed leader.
+	GetLeader() string
+	// Identity returns the identity of the collector in the election.
+	Identity() string
+	// Subscribe registers callbacks that are invoked when the leadership changes and returns a function that
+	// removes them again. OnStartedLeading is invoked right away if the collector is already the leader.
+	Subscribe(callbacks Callbacks) (unsubscribe func())
+	// StepDown gives up the leadership of the collector for the lease duration, so that another collector
+	// can take over. It affects all the subscribers.
+	StepDown()
+}
+
+var (
+	_ LeaderElector             = (*leaderElectorExtension)(nil)
+	_ leaderelection.Leadership = (*leaderElectorExtension)(nil)
+)
+
+// leaderElectorExtension runs one election shared by all its subscribers. Extensions are started before and
+// shut down after the pipelines, so the subscribers stop their leader-gated work before the lease is released.
+type leaderElectorExtension struct {
+	params extension.CreateSettings
+	cfg    *Config
+
+	candidate *leaderelection.Candidate
+}
+
+func newLeaderElectorExtension(params extension.CreateSettings, cfg *Config) *leaderElectorExtension {
+	return &leaderElectorExtension{
+		params: params,
+		cfg:    cfg,
+	}
+}
+
+func (e *leaderElectorExtension) Start(ctx context.Context, _ component.Host) error {
+	e.params.TelemetrySettings.Logger.Info("Creating leader elector...",
+		zap.String("backend", e.cfg.Backend))
+
+	candidate, err := leaderelection.NewCandidate(e.cfg.Config, e.params.ID, e.params.TelemetrySettings, metadata.Tracer(e.params.TelemetrySettings))
+	if err != nil {
+		return fmt.Errorf("failed to create leader elector: %w", err)
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	e.candidate = candidate
+	candidate.Start()
+	return nil
+}
+
+func (e *leaderElectorExtension) Shutdown(ctx context.Context) error {
+	if e.candidate == nil {
+		return nil
+	}
+	return e.candidate.Shutdown(ctx)
+}
+
+// IsLeader returns false if the extension is not started.
+func (e *leaderElectorExtension) IsLeader() bool {
+	if e.candidate == nil {
+		return false
+	}
+	return e.candidate.IsLeader()
+}
+
+// GetLeader returns an empty identity if the extension is not started.
+func (e *leaderElectorExtension) GetLeader() string {
+	if e.candidate == nil {
+		return ""
+	}
+	return e.candidate.GetLeader()
+}
+
+// Identity returns an empty identity if the extension is not started.
+func (e *leaderElectorExtension) Identity() string {
+	if e.candidate == nil {
+		return ""
+	}
+	return e.candidate.Identity()
+}
+
+// Subscribe does not register the callbacks if the extension is not started, because they would never be
+// invoked.
+func (e *leaderElectorExtension) Subscribe(callbacks Callbacks) (unsubscribe func()) {
+	if e.candidate == nil {
+		e.params.TelemetrySettings.Logger.Warn("Leader elector is not started, the subscription is ignored")
+		return func() {}
+	}
+	return e.candidate.Subscribe(callbacks)
+}
+
+// StepDown does nothing if the extension is not started.
+func (e *leaderElectorExtension) StepDown() {
+	if e.candidate == nil {
+		return
+	}
+	e.candidate.StepDown()
+}
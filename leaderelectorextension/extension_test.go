@@ -0,0 +1,67 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package leaderelectorextension
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/extension/extensiontest"
+
+	"github.com/skhalash/leaderreceivercreator/internal/leaderelection"
+)
+
+func TestSharedLeadership(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Backend = leaderelection.BackendMemory
+	cfg.LeaseName = t.Name()
+
+	ext, err := createExtension(context.Background(), extensiontest.NewNopCreateSettings(), cfg)
+	require.NoError(t, err)
+	require.NoError(t, ext.Start(context.Background(), componenttest.NewNopHost()))
+
+	elector := ext.(LeaderElector)
+	var first, second atomic.Bool
+	for _, leading := range []*atomic.Bool{&first, &second} {
+		leading := leading
+		elector.Subscribe(Callbacks{
+			OnStartedLeading: func(context.Context) { leading.Store(true) },
+			OnStoppedLeading: func() { leading.Store(false) },
+		})
+	}
+
+	assert.Eventually(t, func() bool {
+		return first.Load() && second.Load()
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.True(t, elector.IsLeader())
+	assert.Equal(t, elector.Identity(), elector.GetLeader())
+
+	require.NoError(t, ext.Shutdown(context.Background()))
+	assert.False(t, first.Load())
+	assert.False(t, second.Load())
+}
+
+func TestNotStarted(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Backend = leaderelection.BackendMemory
+
+	ext, err := createExtension(context.Background(), extensiontest.NewNopCreateSettings(), cfg)
+	require.NoError(t, err)
+
+	elector := ext.(LeaderElector)
+	assert.False(t, elector.IsLeader())
+	assert.Empty(t, elector.GetLeader())
+	assert.Empty(t, elector.Identity())
+	elector.StepDown()
+	unsubscribe := elector.Subscribe(Callbacks{
+		OnStartedLeading: func(context.Context) { assert.Fail(t, "callback must not be invoked") },
+	})
+	unsubscribe()
+	require.NoError(t, ext.Shutdown(context.Background()))
+}
@@ -0,0 +1,34 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package leaderelectorextension // import "github.com/skhalash/leaderreceivercreator/leaderelectorextension"
+
+import (
+	"context"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/extension"
+
+	"github.com/skhalash/leaderreceivercreator/internal/leaderelection"
+	"github.com/skhalash/leaderreceivercreator/leaderelectorextension/internal/metadata"
+)
+
+// NewFactory creates a factory for the leader_elector extension.
+func NewFactory() extension.Factory {
+	return extension.NewFactory(
+		metadata.Type,
+		createDefaultConfig,
+		createExtension,
+		metadata.ExtensionStability,
+	)
+}
+
+func createDefaultConfig() component.Config {
+	return &Config{
+		Config: leaderelection.NewDefaultConfig(),
+	}
+}
+
+func createExtension(_ context.Context, params extension.CreateSettings, cfg component.Config) (extension.Extension, error) {
+	return newLeaderElectorExtension(params, cfg.(*Config)), nil
+}
@@ -0,0 +1,90 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package leaderexporter // import "github.com/skhalash/leaderreceivercreator/leaderexporter"
+
+import (
+	"fmt"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/confmap"
+
+	"github.com/skhalash/leaderreceivercreator/internal/leaderelection"
+	"github.com/skhalash/leaderreceivercreator/leaderexporter/internal/metadata"
+)
+
+const (
+	// exporterConfigKey is the config key name used to specify the inner exporter.
+	exporterConfigKey = "exporter"
+)
+
+// exporterConfig describes the inner exporter.
+type exporterConfig struct {
+	// id is the id of the inner exporter (ie <exporter type>/<id>).
+	id component.ID
+	// config is the map configured by the user in the config file. The keys and values are arbitrarily
+	// configured by the user.
+	config map[string]any
+}
+
+var (
+	_ confmap.Unmarshaler       = (*Config)(nil)
+	_ component.ConfigValidator = (*Config)(nil)
+)
+
+// Config defines configuration for the leader_exporter exporter.
+type Config struct {
+	LeaderElection leaderelection.Config `mapstructure:"leader_election"`
+	// LeaderElector is the ID of a leader_elector extension whose election gates the inner exporter.
+	// If it is set, the leader_election section is ignored.
+	LeaderElector *component.ID `mapstructure:"leader_elector"`
+
+	// exporterConfigs holds the inner exporter. Only one is allowed, which is checked by Validate.
+	exporterConfigs []exporterConfig
+}
+
+func (cfg *Config) Unmarshal(componentParser *confmap.Conf) error {
+	if componentParser == nil {
+		// Nothing to do if there is no config given.
+		return nil
+	}
+
+	if err := componentParser.Unmarshal(cfg, confmap.WithIgnoreUnused()); err != nil {
+		return err
+	}
+
+	exportersConfig, err := componentParser.Sub(exporterConfigKey)
+	if err != nil {
+		return fmt.Errorf("unable to extract key %v: %w", exporterConfigKey, err)
+	}
+
+	for exporterKey := range exportersConfig.ToStringMap() {
+		innerConfig, err := exportersConfig.Sub(exporterKey)
+		if err != nil {
+			return fmt.Errorf("unable to extract exporter key %v: %w", exporterKey, err)
+		}
+
+		id := component.ID{}
+		if err := id.UnmarshalText([]byte(exporterKey)); err != nil {
+			return fmt.Errorf("%s%s%s: failed to parse exporter id %v: %w", exporterConfigKey, confmap.KeyDelimiter, exporterKey, exporterKey, err)
+		}
+		cfg.exporterConfigs = append(cfg.exporterConfigs, exporterConfig{
+			id:     id,
+			config: innerConfig.ToStringMap(),
+		})
+	}
+
+	return nil
+}
+
+// Validate checks the inner exporter. The leader election settings are validated by component.ValidateConfig.
+func (cfg *Config) Validate() error {
+	if len(cfg.exporterConfigs) != 1 {
+		return fmt.Errorf("%s: exactly one exporter must be configured", exporterConfigKey)
+	}
+	if exporter := cfg.exporterConfigs[0]; exporter.id.Type() == metadata.Type {
+		path := exporterConfigKey + confmap.KeyDelimiter + exporter.id.String()
+		return fmt.Errorf("%s: %s cannot be nested inside itself", path, metadata.Type)
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package leaderexporter
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/confmap"
+	"go.opentelemetry.io/collector/confmap/confmaptest"
+
+	"github.com/skhalash/leaderreceivercreator/internal/leaderelection"
+	"github.com/skhalash/leaderreceivercreator/leaderexporter/internal/metadata"
+)
+
+func TestLoadConfig(t *testing.T) {
+	t.Parallel()
+
+	cm, err := confmaptest.LoadConf(filepath.Join("testdata", "config.yaml"))
+	require.NoError(t, err)
+	leaderElectorID := component.MustNewIDWithName("leader_elector", "k8s")
+
+	tests := []struct {
+		id          component.ID
+		expected    component.Config
+		expectedErr string
+	}{
+		{
+			id: component.NewID(metadata.Type),
+			expected: &Config{
+				LeaderElection: leaderelection.NewDefaultConfig(),
+				exporterConfigs: []exporterConfig{
+					{
+						id:     component.MustNewIDWithName("otlphttp", "billing"),
+						config: map[string]any{"endpoint": "https://billing.example.com"},
+					},
+				},
+			},
+		},
+		{
+			id: component.NewIDWithName(metadata.Type, "shared"),
+			expected: &Config{
+				LeaderElection: leaderelection.NewDefaultConfig(),
+				LeaderElector:  &leaderElectorID,
+				exporterConfigs: []exporterConfig{
+					{
+						id:     component.MustNewID("debug"),
+						config: map[string]any{},
+					},
+				},
+			},
+		},
+		{
+			id:          component.NewIDWithName(metadata.Type, "multiple"),
+			expectedErr: "exporter: exactly one exporter must be configured",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.id.String(), func(t *testing.T) {
+			factory := NewFactory()
+			cfg := factory.CreateDefaultConfig()
+
+			sub, err := cm.Sub(tt.id.String())
+			require.NoError(t, err)
+			require.NoError(t, component.UnmarshalConfig(sub, cfg))
+
+			if tt.expectedErr != "" {
+				assert.EqualError(t, component.ValidateConfig(cfg), tt.expectedErr)
+				return
+			}
+			assert.NoError(t, component.ValidateConfig(cfg))
+			assert.Equal(t, tt.expected, cfg)
+		})
+	}
+}
+
+func TestConfigValidateNested(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	require.NoError(t, cfg.Unmarshal(confmap.NewFromStringMap(map[string]any{
+		"exporter": map[string]any{
+			"leader_exporter/inner": map[string]any{},
+		},
+	})))
+	assert.EqualError(t, cfg.Validate(), "exporter::leader_exporter/inner: leader_exporter cannot be nested inside itself")
+}
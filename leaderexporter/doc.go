@@ -0,0 +1,8 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:generate mdatagen metadata.yaml
+
+// Package leaderexporter provides an exporter that runs an inner exporter only while the collector is the
+// leader.
+package leaderexporter // import "github.com/skhalash/leaderreceivercreator/leaderexporter"
@@ -0,0 +1,429 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package leaderexporter // import "github.com/skhalash/leaderreceivercreator/leaderexporter"
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/confmap"
+	"go.opentelemetry.io/collector/exporter"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+
+	"github.com/skhalash/leaderreceivercreator/internal/leaderelection"
+	"github.com/skhalash/leaderreceivercreator/leaderexporter/internal/metadata"
+)
+
+// exporterStopTimeout bounds how long a lost leadership waits for the exports in flight and the shutdown of the
+// inner exporter.
+const exporterStopTimeout = 5 * time.Second
+
+// exporterRestartMaxInterval caps the backoff between the attempts to start the inner exporter on the leader.
+const exporterRestartMaxInterval = 30 * time.Second
+
+// errExporterNotStarted is returned for the data received by the leader while its inner exporter is not
+// started, so that it is retried instead of being dropped.
+var errExporterNotStarted = errors.New("exporter is not started on the leader")
+
+// signals are the signals the exporter is created for.
+type signals struct {
+	logs    bool
+	metrics bool
+	traces  bool
+}
+
+// leaderExporter runs the inner exporter only while the collector is the leader. The data received by a
+// follower is dropped.
+type leaderExporter struct {
+	params  exporter.CreateSettings
+	cfg     *Config
+	signals signals
+
+	host component.Host
+	// factory and config of the inner exporter are resolved on Start, before the election is started.
+	factory        exporter.Factory
+	exporterConfig component.Config
+	// candidate is the election run by the exporter itself, nil if it uses a leader_elector extension.
+	candidate   *leaderelection.Candidate
+	unsubscribe func()
+
+	// lock guards the inner exporter. The data is exported under the read lock, so that the inner
+	// exporter is not shut down while it is in use.
+	lock         sync.RWMutex
+	inner        *innerExporter
+	shuttingDown bool
+
+	// terms counts the leaderships. innerTerm is the leadership the inner exporter was started for, so that a
+	// stop that waited for the lock does not stop the exporter of a later leadership.
+	terms     atomic.Uint64
+	innerTerm uint64
+	// leading is true while the collector is the leader, whether the inner exporter is started or not.
+	leading atomic.Bool
+
+	// stopStarts cancels the attempts to start the inner exporter, starts waits for them to return.
+	stopStarts context.Context
+	cancel     context.CancelFunc
+	starts     sync.WaitGroup
+	newBackOff func() backoff.BackOff
+}
+
+func newLeaderExporter(params exporter.CreateSettings, cfg *Config) *leaderExporter {
+	stopStarts, cancel := context.WithCancel(context.Background())
+	return &leaderExporter{
+		params:     params,
+		cfg:        cfg,
+		stopStarts: stopStarts,
+		cancel:     cancel,
+		newBackOff: func() backoff.BackOff {
+			b := backoff.NewExponentialBackOff()
+			b.MaxInterval = exporterRestartMaxInterval
+			b.MaxElapsedTime = 0
+			return b
+		},
+	}
+}
+
+func (le *leaderExporter) Start(ctx context.Context, host component.Host) error {
+	le.host = host
+
+	// Every replica resolves the inner exporter, so that a misconfiguration fails the startup
+	// instead of surfacing only once the replica becomes the leader.
+	if err := le.resolveExporter(); err != nil {
+		return fmt.Errorf("failed to resolve exporter %s: %w", le.cfg.exporterConfigs[0].id.String(), err)
+	}
+
+	election, candidate, err := leaderelection.NewLeadership(host, le.cfg.LeaderElector, le.cfg.LeaderElection, le.params.ID,
+		le.params.TelemetrySettings, metadata.Tracer(le.params.TelemetrySettings))
+	if err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	le.candidate = candidate
+
+	le.unsubscribe = election.Subscribe(leaderelection.Callbacks{
+		OnStartedLeading: func(ctx context.Context) {
+			le.params.TelemetrySettings.Logger.Info("Elected as leader")
+			term := le.terms.Add(1)
+			le.leading.Store(true)
+			le.superviseExporter(ctx, term)
+		},
+		OnStoppedLeading: func() {
+			le.params.TelemetrySettings.Logger.Info("Lost leadership")
+			le.leading.Store(false)
+			ctx, cancel := context.WithTimeout(context.Background(), exporterStopTimeout)
+			defer cancel()
+			if err := le.stopExporter(ctx); err != nil {
+				le.params.TelemetrySettings.Logger.Error("Failed to stop exporter", zap.Error(err))
+			}
+		},
+	})
+
+	if candidate != nil {
+		candidate.Start()
+	}
+	return nil
+}
+
+// resolveExporter looks up the factory of the inner exporter and loads and validates its config.
+func (le *leaderExporter) resolveExporter() error {
+	inner := le.cfg.exporterConfigs[0]
+
+	factory := le.host.GetFactory(component.KindExporter, inner.id.Type())
+	if factory == nil {
+		return fmt.Errorf("unable to lookup factory for exporter %q", inner.id.String())
+	}
+	exporterFactory, ok := factory.(exporter.Factory)
+	if !ok {
+		return fmt.Errorf("factory for exporter %q is not an exporter factory", inner.id.String())
+	}
+
+	cfg := exporterFactory.CreateDefaultConfig()
+	if err := component.UnmarshalConfig(confmap.NewFromStringMap(inner.config), cfg); err != nil {
+		return fmt.Errorf("failed to load %q exporter config: %w", inner.id.String(), err)
+	}
+	if err := component.ValidateConfig(cfg); err != nil {
+		return fmt.Errorf("invalid %q exporter config: %w", inner.id.String(), err)
+	}
+
+	le.factory = exporterFactory
+	le.exporterConfig = cfg
+	return nil
+}
+
+// superviseExporter starts the inner exporter for the given leadership in the background. If it fails to
+// start, the attempt is repeated after an exponential backoff until it is started, the leadership is lost or
+// the exporter is shut down. The context is cancelled when the leadership is lost, which aborts a start that
+// hangs.
+func (le *leaderExporter) superviseExporter(ctx context.Context, term uint64) {
+	le.starts.Add(1)
+	go func() {
+		defer le.starts.Done()
+
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		stop := context.AfterFunc(le.stopStarts, cancel)
+		defer stop()
+
+		b := le.newBackOff()
+		for {
+			err := le.startExporter(ctx, term)
+			if err == nil || ctx.Err() != nil || le.superseded(term) {
+				return
+			}
+			le.params.TelemetrySettings.Logger.Error("Failed to start exporter, retrying", zap.Error(err))
+			if !waitBackOff(ctx, b) {
+				return
+			}
+		}
+	}()
+}
+
+// waitBackOff waits for the next backoff interval and returns false if ctx is cancelled meanwhile.
+func waitBackOff(ctx context.Context, b backoff.BackOff) bool {
+	timer := time.NewTimer(b.NextBackOff())
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// superseded reports whether the leadership the inner exporter is started for was lost.
+func (le *leaderExporter) superseded(term uint64) bool {
+	return !le.leading.Load() || le.terms.Load() != term
+}
+
+// startExporter creates and starts the inner exporter for all the signals the exporter is created for. The
+// inner exporter is created and started without holding the lock, so that a slow start does not block the
+// data received meanwhile, and is only swapped in if the leadership it was started for is still held.
+func (le *leaderExporter) startExporter(ctx context.Context, term uint64) error {
+	inner, err := le.createExporter(ctx)
+	if err != nil {
+		return err
+	}
+	if err := inner.Start(ctx, le.host); err != nil {
+		// Some of the inner exporters might have been started.
+		return multierr.Combine(
+			fmt.Errorf("failed to start exporter: %w", err),
+			inner.Shutdown(context.Background()))
+	}
+
+	le.lock.Lock()
+	if le.shuttingDown || le.inner != nil || le.superseded(term) {
+		le.lock.Unlock()
+		// The leadership was lost or the exporter shut down while the inner exporter was started.
+		if err := inner.Shutdown(context.Background()); err != nil {
+			return fmt.Errorf("failed to stop exporter: %w", err)
+		}
+		return nil
+	}
+	le.inner = inner
+	le.innerTerm = term
+	le.lock.Unlock()
+	return nil
+}
+
+// createExporter creates the inner exporters of all the signals. If one of them cannot be created, the ones
+// already created are shut down again.
+func (le *leaderExporter) createExporter(ctx context.Context) (*innerExporter, error) {
+	id := le.cfg.exporterConfigs[0].id
+	le.params.TelemetrySettings.Logger.Info("Starting exporter", zap.String("exporter", id.String()))
+
+	// Sets the inner exporter ID to something like otlp/billing/leader_exporter.
+	runParams := le.params
+	runParams.ID = component.NewIDWithName(le.factory.Type(), fmt.Sprintf("%s/%s", id.Name(), le.params.ID))
+	runParams.Logger = runParams.Logger.With(zap.String("name", runParams.ID.String()))
+
+	inner := &innerExporter{}
+	failed := func(err error) (*innerExporter, error) {
+		return nil, multierr.Combine(err, inner.Shutdown(context.Background()))
+	}
+	if le.signals.logs {
+		logs, err := le.factory.CreateLogsExporter(ctx, runParams, le.exporterConfig)
+		if err != nil {
+			return failed(fmt.Errorf("failed to create logs exporter: %w", err))
+		}
+		inner.logs = logs
+	}
+	if le.signals.metrics {
+		metrics, err := le.factory.CreateMetricsExporter(ctx, runParams, le.exporterConfig)
+		if err != nil {
+			return failed(fmt.Errorf("failed to create metrics exporter: %w", err))
+		}
+		inner.metrics = metrics
+	}
+	if le.signals.traces {
+		traces, err := le.factory.CreateTracesExporter(ctx, runParams, le.exporterConfig)
+		if err != nil {
+			return failed(fmt.Errorf("failed to create traces exporter: %w", err))
+		}
+		inner.traces = traces
+	}
+	return inner, nil
+}
+
+// stopExporter stops the inner exporter if it is running. It is safe to call it multiple times. The lock is
+// only taken once the exports in flight are done, so ctx bounds the wait for them as well as the shutdown.
+// If ctx is done first, the inner exporter is stopped in the background as soon as the lock is taken.
+func (le *leaderExporter) stopExporter(ctx context.Context) error {
+	term := le.terms.Load()
+
+	stopped := make(chan error, 1)
+	go func() {
+		le.lock.Lock()
+		defer le.lock.Unlock()
+		stopped <- le.stopExporterLocked(ctx, term)
+	}()
+
+	select {
+	case err := <-stopped:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("failed to stop exporter: %w", ctx.Err())
+	}
+}
+
+// stopExporterLocked stops the inner exporter unless it was started for a leadership after the given term.
+func (le *leaderExporter) stopExporterLocked(ctx context.Context, term uint64) error {
+	if le.inner == nil || le.innerTerm > term {
+		return nil
+	}
+
+	le.params.TelemetrySettings.Logger.Info("Stopping exporter",
+		zap.String("exporter", le.cfg.exporterConfigs[0].id.String()))
+
+	inner := le.inner
+	le.inner = nil
+	if err := inner.Shutdown(ctx); err != nil {
+		return fmt.Errorf("failed to stop exporter: %w", err)
+	}
+	return nil
+}
+
+func (le *leaderExporter) Shutdown(ctx context.Context) error {
+	if le.unsubscribe == nil {
+		return nil
+	}
+
+	le.lock.Lock()
+	le.shuttingDown = true
+	le.lock.Unlock()
+
+	le.cancel()
+	started := make(chan struct{})
+	go func() {
+		le.starts.Wait()
+		close(started)
+	}()
+	select {
+	case <-started:
+	case <-ctx.Done():
+		return fmt.Errorf("failed to wait for the exporter to start: %w", ctx.Err())
+	}
+
+	// The inner exporter must be stopped before the election loop is cancelled, so that another replica
+	// does not start exporting while this one is still running.
+	err := le.stopExporter(ctx)
+	le.unsubscribe()
+
+	// The election of a leader_elector extension is stopped along with the extension.
+	if le.candidate != nil {
+		err = multierr.Combine(err, le.candidate.Shutdown(ctx))
+	}
+	return err
+}
+
+func (le *leaderExporter) consumeLogs(ctx context.Context, ld plog.Logs) error {
+	le.lock.RLock()
+	defer le.lock.RUnlock()
+
+	if le.inner == nil {
+		if le.leading.Load() {
+			return errExporterNotStarted
+		}
+		le.dropped(ld.LogRecordCount())
+		return nil
+	}
+	return le.inner.logs.ConsumeLogs(ctx, ld)
+}
+
+func (le *leaderExporter) consumeMetrics(ctx context.Context, md pmetric.Metrics) error {
+	le.lock.RLock()
+	defer le.lock.RUnlock()
+
+	if le.inner == nil {
+		if le.leading.Load() {
+			return errExporterNotStarted
+		}
+		le.dropped(md.DataPointCount())
+		return nil
+	}
+	return le.inner.metrics.ConsumeMetrics(ctx, md)
+}
+
+func (le *leaderExporter) consumeTraces(ctx context.Context, td ptrace.Traces) error {
+	le.lock.RLock()
+	defer le.lock.RUnlock()
+
+	if le.inner == nil {
+		if le.leading.Load() {
+			return errExporterNotStarted
+		}
+		le.dropped(td.SpanCount())
+		return nil
+	}
+	return le.inner.traces.ConsumeTraces(ctx, td)
+}
+
+func (le *leaderExporter) dropped(count int) {
+	le.params.TelemetrySettings.Logger.Debug("Dropping data received by a follower", zap.Int("items", count))
+}
+
+var _ component.Component = (*innerExporter)(nil)
+
+// innerExporter holds the inner exporters of all signals.
+type innerExporter struct {
+	logs    exporter.Logs
+	metrics exporter.Metrics
+	traces  exporter.Traces
+}
+
+func (w *innerExporter) Start(ctx context.Context, host component.Host) error {
+	var err error
+	for _, c := range []component.Component{w.logs, w.metrics, w.traces} {
+		if c != nil {
+			if e := c.Start(ctx, host); e != nil {
+				err = multierr.Combine(err, e)
+			}
+		}
+	}
+	return err
+}
+
+func (w *innerExporter) Shutdown(ctx context.Context) error {
+	var err error
+	for _, c := range []component.Component{w.logs, w.metrics, w.traces} {
+		if c != nil {
+			if e := c.Shutdown(ctx); e != nil {
+				err = multierr.Combine(err, e)
+			}
+		}
+	}
+	return err
+}
@@ -0,0 +1,240 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package leaderexporter
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/exporter"
+	"go.opentelemetry.io/collector/exporter/exportertest"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+
+	"github.com/skhalash/leaderreceivercreator/internal/leaderelection"
+)
+
+var sinkType = component.MustNewType("sink")
+
+// sinkHost is a host that provides a factory of metrics exporters writing to the given sink.
+type sinkHost struct {
+	component.Host
+	sink *consumertest.MetricsSink
+}
+
+func (h sinkHost) GetFactory(kind component.Kind, componentType component.Type) component.Factory {
+	if kind != component.KindExporter || componentType != sinkType {
+		return nil
+	}
+	return exporter.NewFactory(sinkType, func() component.Config { return &struct{}{} }, exporter.WithMetrics(
+		func(context.Context, exporter.CreateSettings, component.Config) (exporter.Metrics, error) {
+			return sinkExporter{MetricsSink: h.sink}, nil
+		}, component.StabilityLevelDevelopment))
+}
+
+type sinkExporter struct {
+	component.StartFunc
+	component.ShutdownFunc
+	*consumertest.MetricsSink
+}
+
+// newTestExporter creates a metrics exporter that campaigns for the in-memory lease with the given name.
+func newTestExporter(t *testing.T, leaseName string) exporter.Metrics {
+	cfg := createDefaultConfig().(*Config)
+	cfg.LeaderElection.Backend = leaderelection.BackendMemory
+	cfg.LeaderElection.LeaseName = leaseName
+	cfg.exporterConfigs = []exporterConfig{{id: component.NewID(sinkType), config: map[string]any{}}}
+
+	e, err := NewFactory().CreateMetricsExporter(context.Background(), exportertest.NewNopCreateSettings(), cfg)
+	require.NoError(t, err)
+	return e
+}
+
+func testMetrics() pmetric.Metrics {
+	md := pmetric.NewMetrics()
+	md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics().AppendEmpty().SetEmptyGauge().DataPoints().AppendEmpty()
+	return md
+}
+
+// exported exports test metrics and reports whether they reached the sink. The leader refuses the data until
+// its inner exporter is started.
+func exported(t *testing.T, e exporter.Metrics, sink *consumertest.MetricsSink) bool {
+	if err := e.ConsumeMetrics(context.Background(), testMetrics()); err != nil {
+		require.ErrorIs(t, err, errExporterNotStarted)
+		return false
+	}
+	return sink.DataPointCount() > 0
+}
+
+func TestOnlyLeaderExports(t *testing.T) {
+	leaderSink := &consumertest.MetricsSink{}
+	leader := newTestExporter(t, t.Name())
+	followerSink := &consumertest.MetricsSink{}
+	follower := newTestExporter(t, t.Name())
+
+	require.NoError(t, leader.Start(context.Background(), sinkHost{Host: componenttest.NewNopHost(), sink: leaderSink}))
+	require.Eventually(t, func() bool {
+		return exported(t, leader, leaderSink)
+	}, 5*time.Second, 10*time.Millisecond)
+
+	require.NoError(t, follower.Start(context.Background(), sinkHost{Host: componenttest.NewNopHost(), sink: followerSink}))
+	defer func() {
+		require.NoError(t, follower.Shutdown(context.Background()))
+	}()
+	require.NoError(t, follower.ConsumeMetrics(context.Background(), testMetrics()))
+	assert.Equal(t, 0, followerSink.DataPointCount())
+
+	require.NoError(t, leader.Shutdown(context.Background()))
+
+	// The follower starts its inner exporter once it takes over the leadership.
+	assert.Eventually(t, func() bool {
+		return exported(t, follower, followerSink)
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestStartResolvesExporter(t *testing.T) {
+	e := newTestExporter(t, t.Name())
+	cfg := e.(*metricsExporter).Unwrap().(*leaderExporter).cfg
+	cfg.exporterConfigs = []exporterConfig{{id: component.MustNewID("unknown"), config: map[string]any{}}}
+
+	assert.EqualError(t, e.Start(context.Background(), sinkHost{Host: componenttest.NewNopHost()}),
+		`failed to resolve exporter unknown: unable to lookup factory for exporter "unknown"`)
+	require.NoError(t, e.Shutdown(context.Background()))
+}
+
+// blockingExporter is a metrics exporter whose exports block until release is closed.
+type blockingExporter struct {
+	component.StartFunc
+	component.ShutdownFunc
+	consumertest.Consumer
+	consuming chan struct{}
+	release   chan struct{}
+}
+
+func (e blockingExporter) ConsumeMetrics(context.Context, pmetric.Metrics) error {
+	close(e.consuming)
+	<-e.release
+	return nil
+}
+
+func TestStopExporterDoesNotWaitForSlowExport(t *testing.T) {
+	inner := blockingExporter{Consumer: consumertest.NewNop(), consuming: make(chan struct{}), release: make(chan struct{})}
+	le := newLeaderExporter(exportertest.NewNopCreateSettings(), createDefaultConfig().(*Config))
+	le.cfg.exporterConfigs = []exporterConfig{{id: component.NewID(sinkType), config: map[string]any{}}}
+	le.inner = &innerExporter{metrics: inner}
+
+	exported := make(chan error)
+	go func() {
+		exported <- le.consumeMetrics(context.Background(), testMetrics())
+	}()
+	<-inner.consuming
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, le.stopExporter(ctx), context.DeadlineExceeded)
+
+	// The inner exporter is stopped in the background once the export is done.
+	close(inner.release)
+	require.NoError(t, <-exported)
+	assert.Eventually(t, func() bool {
+		le.lock.RLock()
+		defer le.lock.RUnlock()
+		return le.inner == nil
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+// flakyHost is a host that provides a factory of logs and metrics exporters. The metrics exporters fail to be
+// created or started while createFails or startFails is set.
+type flakyHost struct {
+	component.Host
+	sink        *consumertest.MetricsSink
+	createFails atomic.Bool
+	startFails  atomic.Bool
+	starts      atomic.Int32
+	logsStopped atomic.Bool
+}
+
+func (h *flakyHost) GetFactory(kind component.Kind, componentType component.Type) component.Factory {
+	if kind != component.KindExporter || componentType != sinkType {
+		return nil
+	}
+	return exporter.NewFactory(sinkType, func() component.Config { return &struct{}{} },
+		exporter.WithLogs(func(context.Context, exporter.CreateSettings, component.Config) (exporter.Logs, error) {
+			return stoppableLogsExporter{Consumer: consumertest.NewNop(), stopped: &h.logsStopped}, nil
+		}, component.StabilityLevelDevelopment),
+		exporter.WithMetrics(func(context.Context, exporter.CreateSettings, component.Config) (exporter.Metrics, error) {
+			if h.createFails.Load() {
+				return nil, errors.New("create failed")
+			}
+			return sinkExporter{
+				StartFunc: func(context.Context, component.Host) error {
+					h.starts.Add(1)
+					if h.startFails.Load() {
+						return errors.New("start failed")
+					}
+					return nil
+				},
+				MetricsSink: h.sink,
+			}, nil
+		}, component.StabilityLevelDevelopment))
+}
+
+type stoppableLogsExporter struct {
+	component.StartFunc
+	consumertest.Consumer
+	stopped *atomic.Bool
+}
+
+func (e stoppableLogsExporter) Shutdown(context.Context) error {
+	e.stopped.Store(true)
+	return nil
+}
+
+func TestStartExporterShutsDownPartiallyCreatedExporters(t *testing.T) {
+	host := &flakyHost{Host: componenttest.NewNopHost()}
+	host.createFails.Store(true)
+	le := newLeaderExporter(exportertest.NewNopCreateSettings(), createDefaultConfig().(*Config))
+	le.cfg.exporterConfigs = []exporterConfig{{id: component.NewID(sinkType), config: map[string]any{}}}
+	le.signals = signals{logs: true, metrics: true}
+	le.host = host
+	require.NoError(t, le.resolveExporter())
+
+	term := le.terms.Add(1)
+	le.leading.Store(true)
+	require.EqualError(t, le.startExporter(context.Background(), term), "failed to create metrics exporter: create failed")
+	assert.True(t, host.logsStopped.Load())
+	assert.ErrorIs(t, le.consumeMetrics(context.Background(), testMetrics()), errExporterNotStarted)
+}
+
+func TestLeaderRetriesExporterStart(t *testing.T) {
+	host := &flakyHost{Host: componenttest.NewNopHost(), sink: &consumertest.MetricsSink{}}
+	host.startFails.Store(true)
+	e := newTestExporter(t, t.Name())
+	e.(*metricsExporter).Unwrap().(*leaderExporter).newBackOff = func() backoff.BackOff {
+		return backoff.NewConstantBackOff(10 * time.Millisecond)
+	}
+	require.NoError(t, e.Start(context.Background(), host))
+	defer func() {
+		require.NoError(t, e.Shutdown(context.Background()))
+	}()
+
+	// The leader refuses the data while its inner exporter fails to start.
+	require.Eventually(t, func() bool {
+		return host.starts.Load() > 1
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.ErrorIs(t, e.ConsumeMetrics(context.Background(), testMetrics()), errExporterNotStarted)
+
+	host.startFails.Store(false)
+	assert.Eventually(t, func() bool {
+		return exported(t, e, host.sink)
+	}, 5*time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,104 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package leaderexporter // import "github.com/skhalash/leaderreceivercreator/leaderexporter"
+
+import (
+	"context"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/exporter"
+
+	"github.com/skhalash/leaderreceivercreator/internal/leaderelection"
+	"github.com/skhalash/leaderreceivercreator/internal/sharedcomponent"
+	"github.com/skhalash/leaderreceivercreator/leaderexporter/internal/metadata"
+)
+
+// exporters are shared by the signals created from the same config, so that they follow one election.
+var exporters = sharedcomponent.NewSharedComponents()
+
+// exporterCapabilities are conservative, because the capabilities of the inner exporter are only known once
+// it is created.
+var exporterCapabilities = consumer.Capabilities{MutatesData: true}
+
+// NewFactory creates a factory for the leader_exporter exporter.
+func NewFactory() exporter.Factory {
+	return exporter.NewFactory(
+		metadata.Type,
+		createDefaultConfig,
+		exporter.WithLogs(createLogsExporter, metadata.LogsStability),
+		exporter.WithMetrics(createMetricsExporter, metadata.MetricsStability),
+		exporter.WithTraces(createTracesExporter, metadata.TracesStability),
+	)
+}
+
+func createDefaultConfig() component.Config {
+	return &Config{
+		LeaderElection: leaderelection.NewDefaultConfig(),
+	}
+}
+
+func getOrAddExporter(params exporter.CreateSettings, cfg component.Config) (*sharedcomponent.SharedComponent, *leaderExporter) {
+	e := exporters.GetOrAdd(cfg, func() component.Component {
+		return newLeaderExporter(params, cfg.(*Config))
+	})
+	return e, e.Unwrap().(*leaderExporter)
+}
+
+func createLogsExporter(
+	_ context.Context,
+	params exporter.CreateSettings,
+	cfg component.Config,
+) (exporter.Logs, error) {
+	shared, le := getOrAddExporter(params, cfg)
+	le.signals.logs = true
+	logs, err := consumer.NewLogs(le.consumeLogs, consumer.WithCapabilities(exporterCapabilities))
+	if err != nil {
+		return nil, err
+	}
+	return &logsExporter{SharedComponent: shared, Logs: logs}, nil
+}
+
+func createMetricsExporter(
+	_ context.Context,
+	params exporter.CreateSettings,
+	cfg component.Config,
+) (exporter.Metrics, error) {
+	shared, le := getOrAddExporter(params, cfg)
+	le.signals.metrics = true
+	metrics, err := consumer.NewMetrics(le.consumeMetrics, consumer.WithCapabilities(exporterCapabilities))
+	if err != nil {
+		return nil, err
+	}
+	return &metricsExporter{SharedComponent: shared, Metrics: metrics}, nil
+}
+
+func createTracesExporter(
+	_ context.Context,
+	params exporter.CreateSettings,
+	cfg component.Config,
+) (exporter.Traces, error) {
+	shared, le := getOrAddExporter(params, cfg)
+	le.signals.traces = true
+	traces, err := consumer.NewTraces(le.consumeTraces, consumer.WithCapabilities(exporterCapabilities))
+	if err != nil {
+		return nil, err
+	}
+	return &tracesExporter{SharedComponent: shared, Traces: traces}, nil
+}
+
+type logsExporter struct {
+	*sharedcomponent.SharedComponent
+	consumer.Logs
+}
+
+type metricsExporter struct {
+	*sharedcomponent.SharedComponent
+	consumer.Metrics
+}
+
+type tracesExporter struct {
+	*sharedcomponent.SharedComponent
+	consumer.Traces
+}
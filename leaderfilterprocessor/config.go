@@ -0,0 +1,20 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package leaderfilterprocessor // import "github.com/skhalash/leaderreceivercreator/leaderfilterprocessor"
+
+import (
+	"go.opentelemetry.io/collector/component"
+
+	"github.com/skhalash/leaderreceivercreator/internal/leaderelection"
+)
+
+var _ component.Config = (*Config)(nil)
+
+// Config defines configuration for the leader_filter processor.
+type Config struct {
+	LeaderElection leaderelection.Config `mapstructure:"leader_election"`
+	// LeaderElector is the ID of a leader_elector extension whose election gates the data.
+	// If it is set, the leader_election section is ignored.
+	LeaderElector *component.ID `mapstructure:"leader_elector"`
+}
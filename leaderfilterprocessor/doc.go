@@ -0,0 +1,8 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:generate mdatagen metadata.yaml
+
+// Package leaderfilterprocessor provides a processor that forwards the data only while the collector is the
+// leader and drops it otherwise.
+package leaderfilterprocessor // import "github.com/skhalash/leaderreceivercreator/leaderfilterprocessor"
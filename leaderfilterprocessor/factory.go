@@ -0,0 +1,85 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package leaderfilterprocessor // import "github.com/skhalash/leaderreceivercreator/leaderfilterprocessor"
+
+import (
+	"context"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/processor"
+	"go.opentelemetry.io/collector/processor/processorhelper"
+
+	"github.com/skhalash/leaderreceivercreator/internal/leaderelection"
+	"github.com/skhalash/leaderreceivercreator/internal/sharedcomponent"
+	"github.com/skhalash/leaderreceivercreator/leaderfilterprocessor/internal/metadata"
+)
+
+// filters are shared by the processors created from the same config, so that they follow one election.
+var filters = sharedcomponent.NewSharedComponents()
+
+var processorCapabilities = consumer.Capabilities{MutatesData: false}
+
+// NewFactory creates a factory for the leader_filter processor.
+func NewFactory() processor.Factory {
+	return processor.NewFactory(
+		metadata.Type,
+		createDefaultConfig,
+		processor.WithLogs(createLogsProcessor, metadata.LogsStability),
+		processor.WithMetrics(createMetricsProcessor, metadata.MetricsStability),
+		processor.WithTraces(createTracesProcessor, metadata.TracesStability),
+	)
+}
+
+func createDefaultConfig() component.Config {
+	return &Config{
+		LeaderElection: leaderelection.NewDefaultConfig(),
+	}
+}
+
+func getOrAddFilter(params processor.CreateSettings, cfg component.Config) (*sharedcomponent.SharedComponent, *leaderFilter) {
+	f := filters.GetOrAdd(cfg, func() component.Component {
+		return newLeaderFilter(params, cfg.(*Config))
+	})
+	return f, f.Unwrap().(*leaderFilter)
+}
+
+func createLogsProcessor(
+	ctx context.Context,
+	params processor.CreateSettings,
+	cfg component.Config,
+	nextConsumer consumer.Logs,
+) (processor.Logs, error) {
+	shared, filter := getOrAddFilter(params, cfg)
+	return processorhelper.NewLogsProcessor(ctx, params, cfg, nextConsumer, filter.processLogs,
+		processorhelper.WithStart(shared.Start),
+		processorhelper.WithShutdown(shared.Shutdown),
+		processorhelper.WithCapabilities(processorCapabilities))
+}
+
+func createMetricsProcessor(
+	ctx context.Context,
+	params processor.CreateSettings,
+	cfg component.Config,
+	nextConsumer consumer.Metrics,
+) (processor.Metrics, error) {
+	shared, filter := getOrAddFilter(params, cfg)
+	return processorhelper.NewMetricsProcessor(ctx, params, cfg, nextConsumer, filter.processMetrics,
+		processorhelper.WithStart(shared.Start),
+		processorhelper.WithShutdown(shared.Shutdown),
+		processorhelper.WithCapabilities(processorCapabilities))
+}
+
+func createTracesProcessor(
+	ctx context.Context,
+	params processor.CreateSettings,
+	cfg component.Config,
+	nextConsumer consumer.Traces,
+) (processor.Traces, error) {
+	shared, filter := getOrAddFilter(params, cfg)
+	return processorhelper.NewTracesProcessor(ctx, params, cfg, nextConsumer, filter.processTraces,
+		processorhelper.WithStart(shared.Start),
+		processorhelper.WithShutdown(shared.Shutdown),
+		processorhelper.WithCapabilities(processorCapabilities))
+}
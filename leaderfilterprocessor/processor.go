@@ -0,0 +1,136 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package leaderfilterprocessor // import "github.com/skhalash/leaderreceivercreator/leaderfilterprocessor"
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/collector/processor"
+	"go.opentelemetry.io/collector/processor/processorhelper"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.uber.org/zap"
+
+	"github.com/skhalash/leaderreceivercreator/internal/leaderelection"
+	"github.com/skhalash/leaderreceivercreator/leaderfilterprocessor/internal/metadata"
+)
+
+// leaderFilter forwards the data only while the collector is the leader. The data received by a follower is
+// dropped and counted.
+type leaderFilter struct {
+	params processor.CreateSettings
+	cfg    *Config
+
+	leading   atomic.Bool
+	telemetry *metadata.TelemetryBuilder
+	// logsAttrs, metricsAttrs and tracesAttrs tag the dropped items with the ID of the processor and the signal.
+	logsAttrs    metric.MeasurementOption
+	metricsAttrs metric.MeasurementOption
+	tracesAttrs  metric.MeasurementOption
+
+	// candidate is the election run by the processor itself, nil if it uses a leader_elector extension.
+	candidate   *leaderelection.Candidate
+	unsubscribe func()
+}
+
+func newLeaderFilter(params processor.CreateSettings, cfg *Config) *leaderFilter {
+	attrs := func(signal string) metric.MeasurementOption {
+		return metric.WithAttributeSet(attribute.NewSet(
+			attribute.String("processor", params.ID.String()),
+			attribute.String("signal", signal),
+		))
+	}
+	return &leaderFilter{
+		params:       params,
+		cfg:          cfg,
+		logsAttrs:    attrs("logs"),
+		metricsAttrs: attrs("metrics"),
+		tracesAttrs:  attrs("traces"),
+	}
+}
+
+func (f *leaderFilter) Start(ctx context.Context, host component.Host) error {
+	telemetry, err := metadata.NewTelemetryBuilder(f.params.TelemetrySettings)
+	if err != nil {
+		return fmt.Errorf("failed to create telemetry: %w", err)
+	}
+
+	election, candidate, err := leaderelection.NewLeadership(host, f.cfg.LeaderElector, f.cfg.LeaderElection, f.params.ID,
+		f.params.TelemetrySettings, metadata.Tracer(f.params.TelemetrySettings))
+	if err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	f.telemetry = telemetry
+	f.candidate = candidate
+
+	f.unsubscribe = election.Subscribe(leaderelection.Callbacks{
+		OnStartedLeading: func(context.Context) {
+			f.params.TelemetrySettings.Logger.Info("Elected as leader, forwarding data")
+			f.leading.Store(true)
+		},
+		OnStoppedLeading: func() {
+			f.params.TelemetrySettings.Logger.Info("Lost leadership, dropping data")
+			f.leading.Store(false)
+		},
+	})
+
+	if candidate != nil {
+		candidate.Start()
+	}
+	return nil
+}
+
+func (f *leaderFilter) Shutdown(ctx context.Context) error {
+	if f.unsubscribe == nil {
+		return nil
+	}
+	f.unsubscribe()
+	f.leading.Store(false)
+
+	// The election of a leader_elector extension is stopped along with the extension.
+	if f.candidate != nil {
+		return f.candidate.Shutdown(ctx)
+	}
+	return nil
+}
+
+func (f *leaderFilter) processLogs(ctx context.Context, ld plog.Logs) (plog.Logs, error) {
+	if f.leading.Load() {
+		return ld, nil
+	}
+	f.drop(ctx, ld.LogRecordCount(), f.logsAttrs)
+	return ld, processorhelper.ErrSkipProcessingData
+}
+
+func (f *leaderFilter) processMetrics(ctx context.Context, md pmetric.Metrics) (pmetric.Metrics, error) {
+	if f.leading.Load() {
+		return md, nil
+	}
+	f.drop(ctx, md.DataPointCount(), f.metricsAttrs)
+	return md, processorhelper.ErrSkipProcessingData
+}
+
+func (f *leaderFilter) processTraces(ctx context.Context, td ptrace.Traces) (ptrace.Traces, error) {
+	if f.leading.Load() {
+		return td, nil
+	}
+	f.drop(ctx, td.SpanCount(), f.tracesAttrs)
+	return td, processorhelper.ErrSkipProcessingData
+}
+
+func (f *leaderFilter) drop(ctx context.Context, count int, attrs metric.MeasurementOption) {
+	f.params.TelemetrySettings.Logger.Debug("Dropping data received by a follower", zap.Int("items", count))
+	if f.telemetry != nil {
+		f.telemetry.LeaderFilterDroppedItems.Add(ctx, int64(count), attrs)
+	}
+}
@@ -0,0 +1,81 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package leaderfilterprocessor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/processor"
+	"go.opentelemetry.io/collector/processor/processortest"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+
+	"github.com/skhalash/leaderreceivercreator/internal/leaderelection"
+)
+
+// newTestProcessor creates a metrics processor that campaigns for the in-memory lease with the given name
+// and reports its self-telemetry to the returned reader.
+func newTestProcessor(t *testing.T, leaseName string, next *consumertest.MetricsSink) (processor.Metrics, *sdkmetric.ManualReader) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.LeaderElection.Backend = leaderelection.BackendMemory
+	cfg.LeaderElection.LeaseName = leaseName
+
+	reader := sdkmetric.NewManualReader()
+	params := processortest.NewNopCreateSettings()
+	params.TelemetrySettings.MeterProvider = sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+
+	p, err := NewFactory().CreateMetricsProcessor(context.Background(), params, cfg, next)
+	require.NoError(t, err)
+	return p, reader
+}
+
+func droppedItems(t *testing.T, reader *sdkmetric.ManualReader) int64 {
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &rm))
+
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name == "leader_filter_dropped_items" {
+				return m.Data.(metricdata.Sum[int64]).DataPoints[0].Value
+			}
+		}
+	}
+	return 0
+}
+
+func TestOnlyLeaderForwards(t *testing.T) {
+	host := componenttest.NewNopHost()
+	leaderSink := &consumertest.MetricsSink{}
+	leader, _ := newTestProcessor(t, t.Name(), leaderSink)
+	followerSink := &consumertest.MetricsSink{}
+	follower, followerReader := newTestProcessor(t, t.Name(), followerSink)
+
+	require.NoError(t, leader.Start(context.Background(), host))
+	require.Eventually(t, func() bool {
+		require.NoError(t, leader.ConsumeMetrics(context.Background(), generateLifecycleTestMetrics()))
+		return leaderSink.DataPointCount() > 0
+	}, 5*time.Second, 10*time.Millisecond)
+
+	require.NoError(t, follower.Start(context.Background(), host))
+	defer func() {
+		require.NoError(t, follower.Shutdown(context.Background()))
+	}()
+	require.NoError(t, follower.ConsumeMetrics(context.Background(), generateLifecycleTestMetrics()))
+	assert.Equal(t, 0, followerSink.DataPointCount())
+	assert.Equal(t, int64(1), droppedItems(t, followerReader))
+
+	require.NoError(t, leader.Shutdown(context.Background()))
+
+	// The follower forwards the data once it takes over the leadership.
+	assert.Eventually(t, func() bool {
+		require.NoError(t, follower.ConsumeMetrics(context.Background(), generateLifecycleTestMetrics()))
+		return followerSink.DataPointCount() > 0
+	}, 5*time.Second, 10*time.Millisecond)
+}
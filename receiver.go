@@ -4,18 +4,21 @@
 package leaderreceivercreator
 
 import (
-	"fmt"
 	"context"
-	"os"
-	"path/filepath"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
 
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/consumer"
 	"go.opentelemetry.io/collector/receiver"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/multierr"
 	"go.uber.org/zap"
-	"k8s.io/client-go/kubernetes"
-	"k8s.io/client-go/rest"
-	"k8s.io/client-go/tools/clientcmd"
+
+	"github.com/skhalash/leaderreceivercreator/internal/leaderelection"
+	"github.com/skhalash/leaderreceivercreator/internal/metadata"
 )
 
 var _ receiver.Metrics = (*leaderReceiverCreator)(nil)
@@ -28,9 +31,20 @@ type leaderReceiverCreator struct {
 	nextMetricsConsumer consumer.Metrics
 	nextTracesConsumer  consumer.Traces
 
-	host              component.Host
+	host component.Host
+	// subreceivers are resolved on Start, before the election is started.
+	subreceivers []resolvedReceiverConfig
+	telemetry    *leaderTelemetry
+	tracer       trace.Tracer
+	// candidate is the election run by the receiver creator itself, nil if it uses a leader_elector extension.
+	candidate   *leaderelection.Candidate
+	unsubscribe func()
+	// renewFailure applies the on_renew_failure policy.
+	renewFailure *renewFailureHandler
+
+	lock              sync.Mutex
 	subReceiverRunner *receiverRunner
-	cancel            context.CancelFunc
+	shuttingDown      bool
 }
 
 func newLeaderReceiverCreator(params receiver.CreateSettings, cfg *Config) component.Component {
@@ -43,90 +57,211 @@ func newLeaderReceiverCreator(params receiver.CreateSettings, cfg *Config) compo
 // Start receiver_creator.
 func (ler *leaderReceiverCreator) Start(ctx context.Context, host component.Host) error {
 	ler.host = host
-	ctx = context.Background()
-	ctx, ler.cancel = context.WithCancel(ctx)
+	ler.tracer = metadata.Tracer(ler.params.TelemetrySettings)
 
 	ler.params.TelemetrySettings.Logger.Info("Starting leader election receiver...")
 
-	client, err := ler.newClient()
+	// Every replica resolves the subreceivers, so that a misconfiguration fails the startup
+	// instead of surfacing only once the replica becomes the leader.
+	subreceivers := make([]resolvedReceiverConfig, 0, len(ler.cfg.subreceiverConfigs))
+	for _, receiver := range ler.cfg.subreceiverConfigs {
+		subreceiver, err := resolveReceiverConfig(ctx, ler.tracer, host, receiver)
+		if err != nil {
+			return fmt.Errorf("failed to resolve subreceiver %s: %w", receiver.id.String(), err)
+		}
+		subreceivers = append(subreceivers, subreceiver)
+	}
+	ler.subreceivers = subreceivers
+
+	election, candidate, err := leaderelection.NewLeadership(host, ler.cfg.LeaderElector, ler.cfg.LeaderElection, ler.params.ID,
+		ler.params.TelemetrySettings, ler.tracer)
 	if err != nil {
-		return fmt.Errorf("failed to create Kubernetes client: %w", err)
+		return err
 	}
 
-	ler.params.TelemetrySettings.Logger.Info("Creating leader elector...")
+	telemetry, err := newLeaderTelemetry(ler.params, election.Identity())
+	if err != nil {
+		return fmt.Errorf("failed to create telemetry: %w", err)
+	}
+	status := newStatusReporter(ler.params.TelemetrySettings)
 
-	leaderElector, err := newLeaderElector(
-		client,
-		func(ctx context.Context) {
-			ler.params.TelemetrySettings.Logger.Info("Elected as leader")
-			if err := ler.startSubReceiver(); err != nil {
-				ler.params.TelemetrySettings.Logger.Error("Failed to start subreceiver", zap.Error(err))
+	if err := ctx.Err(); err != nil {
+		telemetry.shutdown()
+		return err
+	}
+	ler.telemetry = telemetry
+	ler.candidate = candidate
+
+	restart := restartCallbacks{
+		started: func(count int) {
+			telemetry.setSubreceiversRunning(count)
+			status.subreceiversFailing(nil)
+		},
+		failed: func(err error, failures int) {
+			ler.params.TelemetrySettings.Logger.Error("Failed to start subreceivers, retrying...",
+				zap.Int("failures", failures), zap.Error(err))
+			telemetry.subreceiverStartFailed()
+			if errors.Is(err, errCreateReceiver) {
+				status.subreceiversFailedPermanently(err)
+				return
 			}
+			status.subreceiversFailing(err)
 		},
-		func() {
-			ler.params.TelemetrySettings.Logger.Info("Lost leadership")
-			if err := ler.stopSubReceiver(); err != nil {
-				ler.params.TelemetrySettings.Logger.Error("Failed to stop subreceiver", zap.Error(err))
+		gaveUp: func(err error, failures int) bool {
+			// The election of a leader_elector extension is shared with other components, which must not lose
+			// the leadership because of these subreceivers. A replica running the subreceivers without the
+			// leadership has nothing to give up either. Both keep retrying, so that the subreceivers are not
+			// left stopped for good.
+			if candidate == nil || !candidate.IsLeader() {
+				ler.params.TelemetrySettings.Logger.Error("Failed to start subreceivers, retrying at the maximum interval...",
+					zap.Int("failures", failures))
+				return false
 			}
+			ler.params.TelemetrySettings.Logger.Warn("Failed to start subreceivers, stepping down from the leadership",
+				zap.Int("failures", failures))
+			status.subreceiversFailedPermanently(err)
+			candidate.StepDown()
+			return true
 		},
-	)
-	if err != nil {
-		return fmt.Errorf("failed to create leader elector: %w", err)
 	}
+	startSubreceivers := func(ctx context.Context) {
+		ler.startSubReceiver(ctx, restart)
+	}
+	stopSubreceivers := func(ctx context.Context, superseded func() bool) {
+		if err := ler.stopSubReceiver(ctx, superseded); err != nil {
+			ler.params.TelemetrySettings.Logger.Error("Failed to stop subreceivers", zap.Error(err))
+		}
+		status.subreceiversFailing(nil)
+	}
+	renewFailure := &renewFailureHandler{
+		cfg:       ler.cfg.OnRenewFailure,
+		identity:  election.Identity(),
+		logger:    ler.params.TelemetrySettings.Logger,
+		telemetry: telemetry,
+		startSubreceivers: func() {
+			startSubreceivers(context.Background())
+		},
+		stopSubreceivers: stopSubreceivers,
+	}
+	ler.renewFailure = renewFailure
+
+	ler.unsubscribe = election.Subscribe(leaderelection.Callbacks{
+		OnStartedLeading: func(ctx context.Context) {
+			ler.params.TelemetrySettings.Logger.Info("Elected as leader")
+			telemetry.setLeading(true)
+			status.leadershipChanged()
+			renewFailure.startedLeading()
+			// The subreceivers are started in the trace of the campaign that acquired the leadership,
+			// but they must outlive the context of the leadership.
+			startSubreceivers(trace.ContextWithSpan(context.Background(), trace.SpanFromContext(ctx)))
+		},
+		OnStoppedLeading: func() {
+			ler.params.TelemetrySettings.Logger.Info("Lost leadership")
+			telemetry.setLeading(false)
+			status.leadershipChanged()
+			if !renewFailure.stoppedLeading() {
+				stopSubreceivers(context.Background(), nil)
+			}
+		},
+		OnNewLeader: func(identity string) {
+			telemetry.newLeader(identity, election.Identity())
+			renewFailure.newLeader(identity)
+		},
+		OnRenew: func(latency time.Duration, err error) {
+			telemetry.leaseRenewed(latency)
+			status.leaseRenewed(err)
+			renewFailure.renewed(err)
+		},
+		OnBackendError: func(err error) {
+			status.backendReached(err)
+			renewFailure.backendReached(err)
+		},
+	})
 
-	leaderElector.Run(ctx)
+	if candidate != nil {
+		candidate.Start()
+	}
 	return nil
 }
 
-func (ler *leaderReceiverCreator) newClient() (kubernetes.Interface, error) {
-	kubeConfigPath := filepath.Join(os.Getenv("HOME"), ".kube/config")
+// startSubReceiver starts the subreceivers in the background, restarting them until they are started or
+// stopped.
+func (ler *leaderReceiverCreator) startSubReceiver(ctx context.Context, callbacks restartCallbacks) {
+	ler.lock.Lock()
+	defer ler.lock.Unlock()
 
-	config, err := rest.InClusterConfig()
-	if err != nil {
-		ler.params.TelemetrySettings.Logger.Warn("Cannot find in cluster config", zap.Error(err))
-		config, err = clientcmd.BuildConfigFromFlags("", kubeConfigPath)
-		if err != nil {
-			ler.params.TelemetrySettings.Logger.Error("Cannot build ClientConfig", zap.Error(err))
-			return nil, err
-		}
-	}
-	client, err := kubernetes.NewForConfig(config)
-	if err != nil {
-		ler.params.TelemetrySettings.Logger.Error("Cannot create Kubernetes client", zap.Error(err))
-		return nil, err
+	if ler.shuttingDown || ler.subReceiverRunner != nil {
+		return
 	}
-	return client, nil
-}
 
-func (ler *leaderReceiverCreator) startSubReceiver() error {
-	ler.params.TelemetrySettings.Logger.Info("Starting subreceiver",
-		zap.String("name", ler.cfg.subreceiverConfig.id.String()))
+	ler.params.TelemetrySettings.Logger.Info("Starting subreceivers",
+		zap.Stringers("names", ler.cfg.subreceiverIDs()))
 
-	ler.subReceiverRunner = newReceiverRunner(ler.params, ler.host)
-	if err := ler.subReceiverRunner.start(
-		receiverConfig{
-			id:     ler.cfg.subreceiverConfig.id,
-			config: ler.cfg.subreceiverConfig.config,
-		},
+	runner := newReceiverRunner(ler.params, ler.host, ler.tracer)
+	runner.supervise(
+		ctx,
+		ler.cfg.RestartOnFailure,
+		ler.subreceivers,
 		ler.nextLogsConsumer,
 		ler.nextMetricsConsumer,
 		ler.nextTracesConsumer,
-	); err != nil {
-		return fmt.Errorf("failed to start subreceiver %s: %w", ler.cfg.subreceiverConfig.id.String(), err)
-	}
-	return nil
+		callbacks,
+	)
+	ler.subReceiverRunner = runner
 }
 
-func (ler *leaderReceiverCreator) stopSubReceiver() error {
-	ler.params.TelemetrySettings.Logger.Info("Stopping subreceiver",
-		zap.String("name", ler.cfg.subreceiverConfig.id.String()))
+// stopSubReceiver stops the subreceivers if they are running, unless superseded is set and returns true. It
+// is safe to call it multiple times. The lock is only held to take the runner, so that starting the
+// subreceivers again does not wait for a slow stop.
+func (ler *leaderReceiverCreator) stopSubReceiver(ctx context.Context, superseded func() bool) (err error) {
+	ler.lock.Lock()
+	runner := ler.subReceiverRunner
+	if runner == nil || (superseded != nil && superseded()) {
+		ler.lock.Unlock()
+		return nil
+	}
+	ler.subReceiverRunner = nil
+	ler.lock.Unlock()
+
+	ctx, span := ler.tracer.Start(ctx, "stop_subreceivers")
+	defer func() {
+		endSpan(span, err)
+	}()
+
+	ler.params.TelemetrySettings.Logger.Info("Stopping subreceivers",
+		zap.Stringers("names", ler.cfg.subreceiverIDs()))
 
-	ler.subReceiverRunner.shutdown(context.Background())
+	// The restart supervisor is stopped first, so that it does not report the subreceivers as running.
+	err = runner.shutdown(ctx)
+	ler.telemetry.setSubreceiversRunning(0)
+	if err != nil {
+		return fmt.Errorf("failed to stop subreceivers: %w", err)
+	}
 	return nil
 }
 
 // Shutdown stops the receiver_creator and all its receivers started at runtime.
-func (ler *leaderReceiverCreator) Shutdown(context.Context) error {
-	ler.cancel()
-	return nil
+func (ler *leaderReceiverCreator) Shutdown(ctx context.Context) error {
+	if ler.unsubscribe == nil {
+		return nil
+	}
+
+	ler.lock.Lock()
+	ler.shuttingDown = true
+	ler.lock.Unlock()
+	ler.renewFailure.shutdown()
+
+	// The subreceiver must be stopped before the election loop is cancelled, because cancelling
+	// the loop may release the lease and another replica must not start its subreceiver while
+	// this one is still running.
+	err := ler.stopSubReceiver(ctx, nil)
+	ler.unsubscribe()
+
+	// The election of a leader_elector extension is stopped along with the extension.
+	if ler.candidate != nil {
+		err = multierr.Combine(err, ler.candidate.Shutdown(ctx))
+	}
+
+	ler.telemetry.shutdown()
+	return err
 }
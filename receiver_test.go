@@ -0,0 +1,409 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package leaderreceivercreator
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/extension/extensiontest"
+	"go.opentelemetry.io/collector/receiver"
+	"go.opentelemetry.io/collector/receiver/receivertest"
+
+	"github.com/skhalash/leaderreceivercreator/internal/leaderelection"
+	"github.com/skhalash/leaderreceivercreator/leaderelectorextension"
+)
+
+var (
+	nopType     = component.MustNewType("nop")
+	failingType = component.MustNewType("failing")
+	invalidType = component.MustNewType("invalid")
+)
+
+// nopHost is a host that provides the nop receiver factory, as well as the factories of receivers
+// that fail to start or have an invalid config.
+type nopHost struct {
+	component.Host
+}
+
+func (nopHost) GetFactory(kind component.Kind, componentType component.Type) component.Factory {
+	if kind != component.KindReceiver {
+		return nil
+	}
+	switch componentType {
+	case nopType:
+		return receivertest.NewNopFactory()
+	case failingType:
+		return newTestFactory(failingType, func() component.Config { return &struct{}{} })
+	case invalidType:
+		return newTestFactory(invalidType, func() component.Config { return &invalidConfig{} })
+	}
+	return nil
+}
+
+// newTestFactory creates a factory of metrics receivers that fail to start.
+func newTestFactory(componentType component.Type, createDefaultConfig component.CreateDefaultConfigFunc) receiver.Factory {
+	return receiver.NewFactory(componentType, createDefaultConfig, receiver.WithMetrics(
+		func(context.Context, receiver.CreateSettings, component.Config, consumer.Metrics) (receiver.Metrics, error) {
+			return failingReceiver{}, nil
+		}, component.StabilityLevelDevelopment))
+}
+
+type invalidConfig struct{}
+
+func (*invalidConfig) Validate() error {
+	return errors.New("invalid config")
+}
+
+type failingReceiver struct {
+	component.ShutdownFunc
+}
+
+func (failingReceiver) Start(context.Context, component.Host) error {
+	return errors.New("failed to start")
+}
+
+// newTestReceiverCreator creates a receiver creator that campaigns for the in-memory lease with the given name.
+func newTestReceiverCreator(leaseName string) *leaderReceiverCreator {
+	cfg := createDefaultConfig().(*Config)
+	cfg.LeaderElection.Backend = leaderelection.BackendMemory
+	cfg.LeaderElection.LeaseName = leaseName
+	cfg.subreceiverConfigs = []receiverConfig{
+		{id: component.MustNewID("nop"), config: map[string]any{}},
+		{id: component.MustNewIDWithName("nop", "2"), config: map[string]any{}},
+	}
+
+	ler := newLeaderReceiverCreator(receivertest.NewNopCreateSettings(), cfg).(*leaderReceiverCreator)
+	ler.nextMetricsConsumer = consumertest.NewNop()
+	return ler
+}
+
+func (ler *leaderReceiverCreator) subReceiverRunning() bool {
+	ler.lock.Lock()
+	defer ler.lock.Unlock()
+	return ler.subReceiverRunner != nil && ler.subReceiverRunner.running()
+}
+
+func TestStartDoesNotBlock(t *testing.T) {
+	ler := newTestReceiverCreator(t.Name())
+
+	require.NoError(t, ler.Start(context.Background(), nopHost{Host: componenttest.NewNopHost()}))
+	assert.Eventually(t, ler.subReceiverRunning, 5*time.Second, 10*time.Millisecond)
+
+	require.NoError(t, ler.Shutdown(context.Background()))
+	assert.False(t, ler.subReceiverRunning())
+}
+
+func TestStartsAllSubReceivers(t *testing.T) {
+	ler := newTestReceiverCreator(t.Name())
+
+	require.NoError(t, ler.Start(context.Background(), nopHost{Host: componenttest.NewNopHost()}))
+	defer func() {
+		require.NoError(t, ler.Shutdown(context.Background()))
+	}()
+	require.Eventually(t, ler.subReceiverRunning, 5*time.Second, 10*time.Millisecond)
+
+	ler.lock.Lock()
+	defer ler.lock.Unlock()
+	assert.Len(t, ler.subReceiverRunner.receivers, 2)
+}
+
+func TestSubReceiversStartAsGroup(t *testing.T) {
+	ler := newTestReceiverCreator(t.Name())
+	ler.cfg.subreceiverConfigs = append(ler.cfg.subreceiverConfigs,
+		receiverConfig{id: component.NewID(failingType), config: map[string]any{}})
+
+	require.NoError(t, ler.Start(context.Background(), nopHost{Host: componenttest.NewNopHost()}))
+	defer func() {
+		require.NoError(t, ler.Shutdown(context.Background()))
+	}()
+
+	// The nop subreceivers are stopped again once the failing one cannot be started.
+	assert.Never(t, ler.subReceiverRunning, 100*time.Millisecond, 10*time.Millisecond)
+}
+
+// flakyHost is a nopHost whose failing subreceivers fail to start the given number of times. If gate is set,
+// they do not try to start before it is closed.
+type flakyHost struct {
+	nopHost
+	failures *atomic.Int32
+	gate     chan struct{}
+}
+
+func (h flakyHost) GetFactory(kind component.Kind, componentType component.Type) component.Factory {
+	if kind != component.KindReceiver || componentType != failingType {
+		return h.nopHost.GetFactory(kind, componentType)
+	}
+	return receiver.NewFactory(failingType, func() component.Config { return &struct{}{} }, receiver.WithMetrics(
+		func(context.Context, receiver.CreateSettings, component.Config, consumer.Metrics) (receiver.Metrics, error) {
+			return flakyReceiver{failures: h.failures, gate: h.gate}, nil
+		}, component.StabilityLevelDevelopment))
+}
+
+type flakyReceiver struct {
+	component.ShutdownFunc
+	failures *atomic.Int32
+	gate     chan struct{}
+}
+
+func (r flakyReceiver) Start(ctx context.Context, _ component.Host) error {
+	if r.gate != nil {
+		select {
+		case <-r.gate:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	if r.failures.Add(-1) >= 0 {
+		return errors.New("failed to start")
+	}
+	return nil
+}
+
+func TestSubReceiversRestart(t *testing.T) {
+	failures := &atomic.Int32{}
+	failures.Store(2)
+	host := flakyHost{nopHost: nopHost{Host: componenttest.NewNopHost()}, failures: failures}
+	ler := newTestReceiverCreator(t.Name())
+	ler.cfg.RestartOnFailure.InitialInterval = 10 * time.Millisecond
+	ler.cfg.subreceiverConfigs = append(ler.cfg.subreceiverConfigs,
+		receiverConfig{id: component.NewID(failingType), config: map[string]any{}})
+
+	require.NoError(t, ler.Start(context.Background(), host))
+	defer func() {
+		require.NoError(t, ler.Shutdown(context.Background()))
+	}()
+
+	assert.Eventually(t, ler.subReceiverRunning, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, int32(-1), failures.Load())
+}
+
+func TestStepDownAfterRestartFailures(t *testing.T) {
+	failures := &atomic.Int32{}
+	failures.Store(math.MaxInt32)
+	gate := make(chan struct{})
+	host := flakyHost{nopHost: nopHost{Host: componenttest.NewNopHost()}, failures: failures, gate: gate}
+	leader := newTestReceiverCreator(t.Name())
+	leader.cfg.RestartOnFailure.InitialInterval = 10 * time.Millisecond
+	leader.cfg.RestartOnFailure.MaxFailures = 2
+	leader.cfg.subreceiverConfigs = append(leader.cfg.subreceiverConfigs,
+		receiverConfig{id: component.NewID(failingType), config: map[string]any{}})
+	recorder := &statusRecorder{}
+	leader.params.TelemetrySettings.ReportStatus = recorder.report
+	follower := newTestReceiverCreator(t.Name())
+
+	require.NoError(t, leader.Start(context.Background(), host))
+	defer func() {
+		require.NoError(t, leader.Shutdown(context.Background()))
+	}()
+	require.Eventually(t, func() bool {
+		return leader.candidate.IsLeader()
+	}, 5*time.Second, 10*time.Millisecond)
+
+	require.NoError(t, follower.Start(context.Background(), host))
+	defer func() {
+		require.NoError(t, follower.Shutdown(context.Background()))
+	}()
+	// The subreceivers of the leader only fail once the follower campaigns.
+	close(gate)
+	// The leader steps down once its subreceivers failed to start twice, so that the follower takes over.
+	assert.Eventually(t, follower.subReceiverRunning, 5*time.Second, 10*time.Millisecond)
+	assert.False(t, leader.candidate.IsLeader())
+	assert.Equal(t, []component.Status{component.StatusRecoverableError, component.StatusPermanentError}, recorder.reported())
+}
+
+// uncreatableHost is a nopHost whose failing subreceivers cannot be created.
+type uncreatableHost struct {
+	nopHost
+}
+
+func (h uncreatableHost) GetFactory(kind component.Kind, componentType component.Type) component.Factory {
+	if kind != component.KindReceiver || componentType != failingType {
+		return h.nopHost.GetFactory(kind, componentType)
+	}
+	return receiver.NewFactory(failingType, func() component.Config { return &struct{}{} }, receiver.WithMetrics(
+		func(context.Context, receiver.CreateSettings, component.Config, consumer.Metrics) (receiver.Metrics, error) {
+			return nil, errors.New("failed to create")
+		}, component.StabilityLevelDevelopment))
+}
+
+func TestPermanentErrorIfSubReceiverCannotBeCreated(t *testing.T) {
+	ler := newTestReceiverCreator(t.Name())
+	ler.cfg.subreceiverConfigs = []receiverConfig{{id: component.NewID(failingType), config: map[string]any{}}}
+	recorder := &statusRecorder{}
+	ler.params.TelemetrySettings.ReportStatus = recorder.report
+
+	require.NoError(t, ler.Start(context.Background(), uncreatableHost{nopHost: nopHost{Host: componenttest.NewNopHost()}}))
+	defer func() {
+		require.NoError(t, ler.Shutdown(context.Background()))
+	}()
+
+	assert.Eventually(t, func() bool {
+		return len(recorder.reported()) > 0
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, []component.Status{component.StatusPermanentError}, recorder.reported())
+}
+
+func TestStartResolvesSubReceivers(t *testing.T) {
+	tests := []struct {
+		name        string
+		id          component.ID
+		expectedErr string
+	}{
+		{
+			name:        "unknown factory",
+			id:          component.MustNewID("unknown"),
+			expectedErr: `failed to resolve subreceiver unknown: unable to lookup factory for receiver "unknown"`,
+		},
+		{
+			name:        "invalid config",
+			id:          component.NewID(invalidType),
+			expectedErr: `failed to resolve subreceiver invalid: invalid "invalid" subreceiver config: invalid config`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ler := newTestReceiverCreator(t.Name())
+			ler.cfg.subreceiverConfigs = append(ler.cfg.subreceiverConfigs, receiverConfig{id: tt.id, config: map[string]any{}})
+
+			assert.EqualError(t, ler.Start(context.Background(), nopHost{Host: componenttest.NewNopHost()}), tt.expectedErr)
+			// The replica does not campaign if the subreceivers cannot be resolved.
+			assert.Nil(t, ler.unsubscribe)
+			require.NoError(t, ler.Shutdown(context.Background()))
+		})
+	}
+}
+
+func TestFailoverOnShutdown(t *testing.T) {
+	host := nopHost{Host: componenttest.NewNopHost()}
+	leader := newTestReceiverCreator(t.Name())
+	recorder := &statusRecorder{}
+	leader.params.TelemetrySettings.ReportStatus = recorder.report
+	follower := newTestReceiverCreator(t.Name())
+
+	require.NoError(t, leader.Start(context.Background(), host))
+	require.Eventually(t, leader.subReceiverRunning, 5*time.Second, 10*time.Millisecond)
+
+	require.NoError(t, follower.Start(context.Background(), host))
+	defer func() {
+		require.NoError(t, follower.Shutdown(context.Background()))
+	}()
+	assert.Never(t, follower.subReceiverRunning, 100*time.Millisecond, 10*time.Millisecond)
+
+	require.NoError(t, leader.Shutdown(context.Background()))
+	assert.False(t, leader.subReceiverRunning())
+	assert.Eventually(t, follower.subReceiverRunning, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestShutdownWithoutStart(t *testing.T) {
+	ler := newTestReceiverCreator(t.Name())
+	require.NoError(t, ler.Shutdown(context.Background()))
+}
+
+// extensionHost is a nopHost that provides the given extensions.
+type extensionHost struct {
+	nopHost
+	extensions map[component.ID]component.Component
+}
+
+func (h extensionHost) GetExtensions() map[component.ID]component.Component {
+	return h.extensions
+}
+
+func TestSharedLeaderElector(t *testing.T) {
+	extID := component.MustNewIDWithName("leader_elector", "shared")
+	extCfg := leaderelectorextension.NewFactory().CreateDefaultConfig().(*leaderelectorextension.Config)
+	extCfg.Backend = leaderelection.BackendMemory
+	extCfg.LeaseName = t.Name()
+	ext, err := leaderelectorextension.NewFactory().CreateExtension(context.Background(), extensiontest.NewNopCreateSettings(), extCfg)
+	require.NoError(t, err)
+
+	host := extensionHost{
+		nopHost:    nopHost{Host: componenttest.NewNopHost()},
+		extensions: map[component.ID]component.Component{extID: ext},
+	}
+	require.NoError(t, ext.Start(context.Background(), host))
+
+	first := newTestReceiverCreator(t.Name() + "/first")
+	first.cfg.LeaderElector = &extID
+	second := newTestReceiverCreator(t.Name() + "/second")
+	second.cfg.LeaderElector = &extID
+
+	require.NoError(t, first.Start(context.Background(), host))
+	require.NoError(t, second.Start(context.Background(), host))
+	// Both receiver creators follow the same election, even though their own lease names differ.
+	assert.Eventually(t, func() bool {
+		return first.subReceiverRunning() && second.subReceiverRunning()
+	}, 5*time.Second, 10*time.Millisecond)
+
+	require.NoError(t, first.Shutdown(context.Background()))
+	require.NoError(t, second.Shutdown(context.Background()))
+	assert.False(t, first.subReceiverRunning())
+	assert.False(t, second.subReceiverRunning())
+	require.NoError(t, ext.Shutdown(context.Background()))
+}
+
+func TestSharedLeaderElectorRetriesAfterRestartFailures(t *testing.T) {
+	extID := component.MustNewIDWithName("leader_elector", "shared")
+	extCfg := leaderelectorextension.NewFactory().CreateDefaultConfig().(*leaderelectorextension.Config)
+	extCfg.Backend = leaderelection.BackendMemory
+	extCfg.LeaseName = t.Name()
+	ext, err := leaderelectorextension.NewFactory().CreateExtension(context.Background(), extensiontest.NewNopCreateSettings(), extCfg)
+	require.NoError(t, err)
+
+	host := extensionHost{
+		nopHost:    nopHost{Host: componenttest.NewNopHost()},
+		extensions: map[component.ID]component.Component{extID: ext},
+	}
+	require.NoError(t, ext.Start(context.Background(), host))
+	defer func() {
+		require.NoError(t, ext.Shutdown(context.Background()))
+	}()
+	elector := ext.(leaderelectorextension.LeaderElector)
+	require.Eventually(t, elector.IsLeader, 5*time.Second, 10*time.Millisecond)
+
+	ler := newTestReceiverCreator(t.Name())
+	ler.cfg.LeaderElector = &extID
+	ler.cfg.RestartOnFailure.InitialInterval = 10 * time.Millisecond
+	ler.cfg.RestartOnFailure.MaxInterval = 10 * time.Millisecond
+	ler.cfg.RestartOnFailure.MaxFailures = 1
+	ler.cfg.subreceiverConfigs = append(ler.cfg.subreceiverConfigs,
+		receiverConfig{id: component.NewID(failingType), config: map[string]any{}})
+	recorder := &statusRecorder{}
+	ler.params.TelemetrySettings.ReportStatus = recorder.report
+	failures := &atomic.Int32{}
+	failures.Store(3)
+
+	require.NoError(t, ler.Start(context.Background(), flakyHost{nopHost: nopHost{Host: host}, failures: failures}))
+	defer func() {
+		require.NoError(t, ler.Shutdown(context.Background()))
+	}()
+
+	// The receiver keeps retrying past max_failures without stepping down the shared election.
+	assert.Eventually(t, ler.subReceiverRunning, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, int32(-1), failures.Load())
+	assert.True(t, elector.IsLeader())
+	assert.Equal(t, []component.Status{component.StatusRecoverableError, component.StatusOK}, recorder.reported())
+}
+
+func TestLeaderElectorNotFound(t *testing.T) {
+	ler := newTestReceiverCreator(t.Name())
+	extID := component.MustNewIDWithName("leader_elector", "missing")
+	ler.cfg.LeaderElector = &extID
+
+	assert.EqualError(t, ler.Start(context.Background(), nopHost{Host: componenttest.NewNopHost()}),
+		`leader elector "leader_elector/missing" not found`)
+	require.NoError(t, ler.Shutdown(context.Background()))
+}
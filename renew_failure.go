@@ -0,0 +1,199 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package leaderreceivercreator
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// subreceiverStopTimeout bounds the stop of the subreceivers running without the leadership.
+const subreceiverStopTimeout = 30 * time.Second
+
+// renewFailureHandler applies the on_renew_failure policy. It decides whether the subreceivers keep running
+// after the leadership is lost because the lease could not be renewed, e.g. because the API server of
+// Kubernetes is unavailable, and starts them on the followers with the run_everywhere policy.
+//
+// The subreceivers are stopped in the background, because the callbacks of the election that end the degraded
+// mode must not block. A stop is skipped if the subreceivers are meant to run again by the time it is carried
+// out, so that a follower never stops them after the replica acquired the leadership again.
+type renewFailureHandler struct {
+	cfg       RenewFailureConfig
+	identity  string
+	logger    *zap.Logger
+	telemetry *leaderTelemetry
+	// startSubreceivers and stopSubreceivers are safe to call if the subreceivers are already running or
+	// stopped. stopSubreceivers keeps the subreceivers running if superseded returns true.
+	startSubreceivers func()
+	stopSubreceivers  func(ctx context.Context, superseded func() bool)
+
+	// term is incremented whenever the subreceivers are meant to run, so that a stop started before is
+	// skipped. It is only written while holding the lock.
+	term atomic.Uint64
+	// stops tracks the stops running in the background.
+	stops sync.WaitGroup
+
+	lock    sync.Mutex
+	leading bool
+	// renewFailing is true if the last renewal of the lease failed.
+	renewFailing bool
+	// degraded is true while the subreceivers run without the leadership.
+	degraded bool
+	// gracePeriod is incremented whenever a grace period starts or ends, so that an expired timer of a
+	// previous grace period is ignored.
+	gracePeriod      int
+	gracePeriodTimer *time.Timer
+	// shutDown is true once shutdown is called, no more stops are started in the background afterwards.
+	shutDown bool
+}
+
+// startedLeading ends the degraded mode, the subreceivers keep running under the leadership.
+func (h *renewFailureHandler) startedLeading() {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	h.renewFailing = false
+	h.degraded = false
+	h.telemetry.setRunningWithoutLeadership(false)
+	h.stopGracePeriodLocked()
+	h.leading = true
+	h.term.Add(1)
+}
+
+// stoppedLeading returns true if the subreceivers keep running although the leadership is lost.
+func (h *renewFailureHandler) stoppedLeading() bool {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	h.leading = false
+	if !h.renewFailing || h.cfg.Policy == RenewFailureStop {
+		return false
+	}
+
+	h.degraded = true
+	h.term.Add(1)
+	h.telemetry.setRunningWithoutLeadership(true)
+	if h.cfg.Policy == RenewFailureKeepRunning {
+		h.logger.Warn("Lost leadership because the lease could not be renewed, keeping subreceivers running",
+			zap.Duration("grace_period", h.cfg.GracePeriod))
+		h.gracePeriod++
+		gracePeriod := h.gracePeriod
+		h.gracePeriodTimer = time.AfterFunc(h.cfg.GracePeriod, func() {
+			h.gracePeriodExpired(gracePeriod)
+		})
+	} else {
+		h.logger.Warn("Lost leadership because the lease could not be renewed, running subreceivers in degraded mode")
+	}
+	return true
+}
+
+func (h *renewFailureHandler) renewed(err error) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	h.renewFailing = err != nil
+}
+
+// newLeader stops the subreceivers running without the leadership once another replica is the leader.
+func (h *renewFailureHandler) newLeader(identity string) {
+	if identity == "" || identity == h.identity {
+		return
+	}
+
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	if h.endDegradedLocked() {
+		h.logger.Info("Observed a new leader, stopping subreceivers", zap.String("leader", identity))
+		h.stopSubreceiversLocked()
+	}
+}
+
+// backendReached runs the subreceivers on a follower with the run_everywhere policy while the backend cannot
+// be reached, as the leader might not be able to renew the lease either.
+func (h *renewFailureHandler) backendReached(err error) {
+	if h.cfg.Policy != RenewFailureRunEverywhere {
+		return
+	}
+
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	if err == nil {
+		if h.endDegradedLocked() {
+			h.logger.Info("Leader election backend reached, stopping subreceivers running in degraded mode")
+			h.stopSubreceiversLocked()
+		}
+		return
+	}
+	if h.leading || h.degraded {
+		return
+	}
+	h.degraded = true
+	h.term.Add(1)
+	h.telemetry.setRunningWithoutLeadership(true)
+	h.logger.Warn("Leader election backend unreachable, running subreceivers in degraded mode", zap.Error(err))
+	h.startSubreceivers()
+}
+
+func (h *renewFailureHandler) gracePeriodExpired(gracePeriod int) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	if h.gracePeriod != gracePeriod || !h.endDegradedLocked() {
+		return
+	}
+	h.logger.Warn("Grace period expired without regaining the leadership, stopping subreceivers")
+	h.stopSubreceiversLocked()
+}
+
+// stopSubreceiversLocked stops the subreceivers in the background, within subreceiverStopTimeout.
+func (h *renewFailureHandler) stopSubreceiversLocked() {
+	if h.shutDown {
+		return
+	}
+	term := h.term.Load()
+	h.stops.Add(1)
+	go func() {
+		defer h.stops.Done()
+		ctx, cancel := context.WithTimeout(context.Background(), subreceiverStopTimeout)
+		defer cancel()
+		h.stopSubreceivers(ctx, func() bool {
+			return h.term.Load() != term
+		})
+	}()
+}
+
+// endDegradedLocked ends the degraded mode of a follower and returns true if it was degraded.
+func (h *renewFailureHandler) endDegradedLocked() bool {
+	if h.leading || !h.degraded {
+		return false
+	}
+	h.degraded = false
+	h.telemetry.setRunningWithoutLeadership(false)
+	h.stopGracePeriodLocked()
+	return true
+}
+
+func (h *renewFailureHandler) stopGracePeriodLocked() {
+	if h.gracePeriodTimer == nil {
+		return
+	}
+	h.gracePeriodTimer.Stop()
+	h.gracePeriodTimer = nil
+	h.gracePeriod++
+}
+
+// shutdown stops the grace period timer and waits for the stops running in the background.
+func (h *renewFailureHandler) shutdown() {
+	h.lock.Lock()
+	h.leading = false
+	h.endDegradedLocked()
+	h.shutDown = true
+	h.lock.Unlock()
+
+	h.stops.Wait()
+}
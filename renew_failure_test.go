@@ -0,0 +1,127 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package leaderreceivercreator
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/receiver/receivertest"
+	"go.uber.org/zap"
+)
+
+func newTestRenewFailureHandler(t *testing.T, cfg RenewFailureConfig, running *atomic.Bool) *renewFailureHandler {
+	telemetry, err := newLeaderTelemetry(receivertest.NewNopCreateSettings(), "self")
+	require.NoError(t, err)
+	t.Cleanup(telemetry.shutdown)
+
+	h := &renewFailureHandler{
+		cfg:               cfg,
+		identity:          "self",
+		logger:            zap.NewNop(),
+		telemetry:         telemetry,
+		startSubreceivers: func() { running.Store(true) },
+		stopSubreceivers: func(_ context.Context, superseded func() bool) {
+			if !superseded() {
+				running.Store(false)
+			}
+		},
+	}
+	t.Cleanup(h.shutdown)
+	return h
+}
+
+func TestRenewFailureStop(t *testing.T) {
+	var running atomic.Bool
+	h := newTestRenewFailureHandler(t, RenewFailureConfig{Policy: RenewFailureStop}, &running)
+
+	h.startedLeading()
+	h.renewed(errors.New("timeout"))
+	assert.False(t, h.stoppedLeading())
+	h.backendReached(errors.New("connection refused"))
+	assert.False(t, running.Load())
+}
+
+func TestRenewFailureKeepRunning(t *testing.T) {
+	var running atomic.Bool
+	h := newTestRenewFailureHandler(t, RenewFailureConfig{
+		Policy:      RenewFailureKeepRunning,
+		GracePeriod: 50 * time.Millisecond,
+	}, &running)
+
+	// The subreceivers stop at once if the leadership is handed over without a failed renewal.
+	h.startedLeading()
+	running.Store(true)
+	assert.False(t, h.stoppedLeading())
+
+	// They keep running until the grace period expires if the renewal failed.
+	h.startedLeading()
+	h.renewed(errors.New("timeout"))
+	assert.True(t, h.stoppedLeading())
+	assert.Equal(t, int64(1), h.telemetry.runningWithoutLeadership())
+	assert.Eventually(t, func() bool { return !running.Load() }, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, int64(0), h.telemetry.runningWithoutLeadership())
+
+	// They keep running if the leadership is acquired again within the grace period.
+	h.startedLeading()
+	running.Store(true)
+	h.renewed(errors.New("timeout"))
+	assert.True(t, h.stoppedLeading())
+	h.startedLeading()
+	time.Sleep(100 * time.Millisecond)
+	assert.True(t, running.Load())
+
+	// They stop as soon as another replica is the leader.
+	h.renewed(errors.New("timeout"))
+	assert.True(t, h.stoppedLeading())
+	h.newLeader("self")
+	assert.True(t, running.Load())
+	h.newLeader("other")
+	assert.Eventually(t, func() bool { return !running.Load() }, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestRenewFailureRunEverywhere(t *testing.T) {
+	var running atomic.Bool
+	h := newTestRenewFailureHandler(t, RenewFailureConfig{Policy: RenewFailureRunEverywhere}, &running)
+
+	// A follower runs the subreceivers while the backend cannot be reached.
+	h.backendReached(errors.New("connection refused"))
+	assert.True(t, running.Load())
+	assert.Equal(t, int64(1), h.telemetry.runningWithoutLeadership())
+	h.backendReached(nil)
+	assert.Eventually(t, func() bool { return !running.Load() }, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, int64(0), h.telemetry.runningWithoutLeadership())
+
+	// The leader keeps running them until another replica is the leader.
+	h.startedLeading()
+	running.Store(true)
+	h.backendReached(errors.New("connection refused"))
+	h.renewed(errors.New("timeout"))
+	assert.True(t, h.stoppedLeading())
+	h.newLeader("other")
+	assert.Eventually(t, func() bool { return !running.Load() }, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestRenewFailureStopDoesNotBlock(t *testing.T) {
+	release := make(chan struct{})
+	stopped := make(chan bool, 1)
+	h := newTestRenewFailureHandler(t, RenewFailureConfig{Policy: RenewFailureRunEverywhere}, &atomic.Bool{})
+	h.stopSubreceivers = func(_ context.Context, superseded func() bool) {
+		<-release
+		stopped <- !superseded()
+	}
+
+	// The callbacks of the election return while the subreceivers are still being stopped.
+	h.backendReached(errors.New("connection refused"))
+	h.backendReached(nil)
+	h.backendReached(errors.New("connection refused"))
+	close(release)
+	// The stop is skipped, because the subreceivers are meant to run again.
+	assert.False(t, <-stopped)
+}
@@ -8,60 +8,229 @@ import (
 	"errors"
 	"fmt"
 	"sync"
+	"time"
 
+	"github.com/cenkalti/backoff/v4"
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/confmap"
 	"go.opentelemetry.io/collector/consumer"
 	rcvr "go.opentelemetry.io/collector/receiver"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/multierr"
 	"go.uber.org/zap"
 )
 
-// receiverRunner handles starting/stopping of a concrete subreceiver instance.
+// receiverRunner handles starting/stopping of a group of subreceiver instances.
 type receiverRunner struct {
 	logger      *zap.Logger
 	params      rcvr.CreateSettings
 	idNamespace component.ID
 	host        component.Host
-	receiver    component.Component
-	lock        *sync.Mutex
+	tracer      trace.Tracer
+	// lock guards the receivers, which are started by the restart supervisor.
+	lock      *sync.Mutex
+	receivers []component.Component
+
+	// cancel stops the restart supervisor and done is closed once it has exited.
+	cancel context.CancelFunc
+	done   chan struct{}
 }
 
-func newReceiverRunner(params rcvr.CreateSettings, host component.Host) *receiverRunner {
+// errCreateReceiver is returned if a subreceiver cannot be created, which a restart is not expected to fix.
+var errCreateReceiver = errors.New("failed creating endpoint-derived receiver")
+
+// restartCallbacks are invoked by the restart supervisor of a receiverRunner. They must not block.
+type restartCallbacks struct {
+	// started is called once the subreceivers are started, with their number.
+	started func(count int)
+	// failed is called after every failed attempt, with the number of consecutive failures.
+	failed func(err error, failures int)
+	// gaveUp is called once restart_on_failure::max_failures attempts failed in a row, with the last error. It
+	// returns true if the leadership is given up, in which case no more attempts are made. Otherwise the
+	// attempts go on at the capped backoff.
+	gaveUp func(err error, failures int) bool
+}
+
+func newReceiverRunner(params rcvr.CreateSettings, host component.Host, tracer trace.Tracer) *receiverRunner {
 	return &receiverRunner{
 		logger:      params.Logger,
 		params:      params,
 		idNamespace: params.ID,
 		host:        host,
+		tracer:      tracer,
 		lock:        &sync.Mutex{},
 	}
 }
 
-func (run *receiverRunner) start(
+// resolvedReceiverConfig is a subreceiver whose factory has been looked up and whose config has been loaded
+// and validated.
+type resolvedReceiverConfig struct {
+	id      component.ID
+	factory rcvr.Factory
+	config  component.Config
+}
+
+// resolveReceiverConfig looks up the factory of the subreceiver and loads and validates its config, so that
+// misconfigured subreceivers are reported on startup rather than when the leadership is acquired.
+func resolveReceiverConfig(
+	ctx context.Context,
+	tracer trace.Tracer,
+	host component.Host,
 	receiver receiverConfig,
+) (_ resolvedReceiverConfig, err error) {
+	ctx, span := tracer.Start(ctx, "resolve_subreceiver", trace.WithAttributes(subreceiverAttribute(receiver.id)))
+	defer func() {
+		endSpan(span, err)
+	}()
+
+	_, lookupSpan := tracer.Start(ctx, "lookup_factory")
+	factory := host.GetFactory(component.KindReceiver, receiver.id.Type())
+	if factory == nil {
+		err = fmt.Errorf("unable to lookup factory for receiver %q", receiver.id.String())
+		endSpan(lookupSpan, err)
+		return resolvedReceiverConfig{}, err
+	}
+	receiverFactory, ok := factory.(rcvr.Factory)
+	if !ok {
+		err = fmt.Errorf("factory for receiver %q is not a receiver factory", receiver.id.String())
+		endSpan(lookupSpan, err)
+		return resolvedReceiverConfig{}, err
+	}
+	endSpan(lookupSpan, nil)
+
+	_, loadSpan := tracer.Start(ctx, "load_config")
+	cfg, err := loadReceiverConfig(receiverFactory, receiver)
+	if err == nil {
+		if validateErr := component.ValidateConfig(cfg); validateErr != nil {
+			err = fmt.Errorf("invalid %q subreceiver config: %w", receiver.id.String(), validateErr)
+		}
+	}
+	endSpan(loadSpan, err)
+	if err != nil {
+		return resolvedReceiverConfig{}, err
+	}
+
+	return resolvedReceiverConfig{
+		id:      receiver.id,
+		factory: receiverFactory,
+		config:  cfg,
+	}, nil
+}
+
+// supervise starts the given subreceivers in the background. If they fail to start, the attempt is repeated
+// after an exponential backoff with jitter until they are started, the runner is shut down or the leadership
+// is given up after restart_on_failure::max_failures attempts failed in a row.
+func (run *receiverRunner) supervise(
+	ctx context.Context,
+	cfg RestartConfig,
+	receivers []resolvedReceiverConfig,
 	logsConsumer consumer.Logs,
 	metricsConsumer consumer.Metrics,
 	tracesConsumer consumer.Traces,
-) error {
-	factory := run.host.GetFactory(component.KindReceiver, receiver.id.Type())
+	callbacks restartCallbacks,
+) {
+	ctx, run.cancel = context.WithCancel(ctx)
+	run.done = make(chan struct{})
 
-	if factory == nil {
-		return fmt.Errorf("unable to lookup factory for receiver %q", receiver.id.String())
+	go func() {
+		defer close(run.done)
+
+		b := cfg.newBackOff()
+		for failures := 0; ; {
+			err := run.start(ctx, failures+1, receivers, logsConsumer, metricsConsumer, tracesConsumer)
+			if err == nil {
+				callbacks.started(len(receivers))
+				return
+			}
+			// A failure caused by the shutdown of the runner is not reported.
+			if ctx.Err() != nil {
+				return
+			}
+			failures++
+			callbacks.failed(err, failures)
+			if cfg.MaxFailures > 0 && failures == cfg.MaxFailures && callbacks.gaveUp(err, failures) {
+				return
+			}
+			if !waitBackOff(ctx, b) {
+				return
+			}
+		}
+	}()
+}
+
+// waitBackOff waits for the next backoff interval and returns false if ctx is cancelled meanwhile.
+func waitBackOff(ctx context.Context, b backoff.BackOff) bool {
+	timer := time.NewTimer(b.NextBackOff())
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
 	}
+}
 
-	receiverFactory := factory.(rcvr.Factory)
+// start starts all the given subreceivers. The group is started as a whole: if any of them fails to start,
+// the ones already started are shut down again.
+func (run *receiverRunner) start(
+	ctx context.Context,
+	attempt int,
+	receivers []resolvedReceiverConfig,
+	logsConsumer consumer.Logs,
+	metricsConsumer consumer.Metrics,
+	tracesConsumer consumer.Traces,
+) (err error) {
+	ctx, span := run.tracer.Start(ctx, "start_subreceivers", trace.WithAttributes(attribute.Int("attempt", attempt)))
+	defer func() {
+		endSpan(span, err)
+	}()
 
-	cfg, _, err := run.loadReceiverConfig(receiverFactory, receiver)
-	if err != nil {
-		return err
+	started := make([]component.Component, 0, len(receivers))
+	for _, receiver := range receivers {
+		r, err := run.startReceiver(ctx, receiver, logsConsumer, metricsConsumer, tracesConsumer)
+		if err != nil {
+			err = fmt.Errorf("failed to start subreceiver %s: %w", receiver.id.String(), err)
+			return multierr.Combine(err, shutdownReceivers(context.Background(), started))
+		}
+		started = append(started, r)
 	}
 
+	run.lock.Lock()
+	defer run.lock.Unlock()
+	run.receivers = started
+	return nil
+}
+
+// running returns true if the subreceivers are started.
+func (run *receiverRunner) running() bool {
+	run.lock.Lock()
+	defer run.lock.Unlock()
+	return len(run.receivers) > 0
+}
+
+func (run *receiverRunner) startReceiver(
+	ctx context.Context,
+	receiver resolvedReceiverConfig,
+	logsConsumer consumer.Logs,
+	metricsConsumer consumer.Metrics,
+	tracesConsumer consumer.Traces,
+) (_ component.Component, err error) {
+	ctx, span := run.tracer.Start(ctx, "start_subreceiver", trace.WithAttributes(subreceiverAttribute(receiver.id)))
+	defer func() {
+		endSpan(span, err)
+	}()
+
+	receiverFactory := receiver.factory
+	cfg := receiver.config
+
 	// Sets dynamically created receiver to something like receiver_creator/1/redis.
-	id := component.NewIDWithName(factory.Type(), fmt.Sprintf("%s/%s", receiver.id.Name(), run.idNamespace))
+	id := component.NewIDWithName(receiverFactory.Type(), fmt.Sprintf("%s/%s", receiver.id.Name(), run.idNamespace))
 
 	wr := &wrappedReceiver{}
 	var createError error
-	if wr.logs, err = run.createLogsRuntimeReceiver(receiverFactory, id, cfg, logsConsumer); err != nil {
+	if wr.logs, err = run.createLogsRuntimeReceiver(ctx, receiverFactory, id, cfg, logsConsumer); err != nil {
 		if errors.Is(err, component.ErrDataTypeIsNotSupported) {
 			run.logger.Info("instantiated receiver doesn't support logs", zap.String("receiver", receiver.id.String()), zap.Error(err))
 			wr.logs = nil
@@ -69,7 +238,7 @@ func (run *receiverRunner) start(
 			createError = multierr.Combine(createError, err)
 		}
 	}
-	if wr.metrics, err = run.createMetricsRuntimeReceiver(receiverFactory, id, cfg, metricsConsumer); err != nil {
+	if wr.metrics, err = run.createMetricsRuntimeReceiver(ctx, receiverFactory, id, cfg, metricsConsumer); err != nil {
 		if errors.Is(err, component.ErrDataTypeIsNotSupported) {
 			run.logger.Info("instantiated receiver doesn't support metrics", zap.String("receiver", receiver.id.String()), zap.Error(err))
 			wr.metrics = nil
@@ -77,7 +246,7 @@ func (run *receiverRunner) start(
 			createError = multierr.Combine(createError, err)
 		}
 	}
-	if wr.traces, err = run.createTracesRuntimeReceiver(receiverFactory, id, cfg, tracesConsumer); err != nil {
+	if wr.traces, err = run.createTracesRuntimeReceiver(ctx, receiverFactory, id, cfg, tracesConsumer); err != nil {
 		if errors.Is(err, component.ErrDataTypeIsNotSupported) {
 			run.logger.Info("instantiated receiver doesn't support traces", zap.String("receiver", receiver.id.String()), zap.Error(err))
 			wr.traces = nil
@@ -87,78 +256,138 @@ func (run *receiverRunner) start(
 	}
 
 	if createError != nil {
-		return fmt.Errorf("failed creating endpoint-derived receiver: %w", createError)
+		return nil, fmt.Errorf("%w: %w", errCreateReceiver, createError)
 	}
 
 	run.params.Logger.Info("Starting subreceiver",
 		zap.String("receiver", receiver.id.String()),
 		zap.Any("config", cfg))
 
-	if err = wr.Start(context.Background(), run.host); err != nil {
-		return fmt.Errorf("failed starting endpoint-derived receiver: %w", createError)
+	startCtx, startSpan := run.tracer.Start(ctx, "start_receiver")
+	err = wr.Start(startCtx, run.host)
+	endSpan(startSpan, err)
+	if err != nil {
+		// Some of the wrapped receivers might have been started.
+		return nil, multierr.Combine(
+			fmt.Errorf("failed starting endpoint-derived receiver: %w", err),
+			wr.Shutdown(context.Background()))
 	}
 
-	run.receiver = wr
-
-	return nil
+	return wr, nil
 }
 
-// shutdown the given receiver.
+// shutdown stops the restart supervisor and the started receivers.
 func (run *receiverRunner) shutdown(ctx context.Context) error {
-	if run.receiver != nil {
-		return run.receiver.Shutdown(ctx)
+	if run.cancel != nil {
+		run.cancel()
+		select {
+		case <-run.done:
+		case <-ctx.Done():
+			return fmt.Errorf("failed to wait for the subreceivers to start: %w", ctx.Err())
+		}
 	}
-	return nil
+
+	run.lock.Lock()
+	receivers := run.receivers
+	run.receivers = nil
+	run.lock.Unlock()
+	return shutdownReceivers(ctx, receivers)
 }
 
-func (run *receiverRunner) loadReceiverConfig(
+// shutdownReceivers shuts the receivers down in the reverse order.
+func shutdownReceivers(ctx context.Context, receivers []component.Component) error {
+	var err error
+	for i := len(receivers) - 1; i >= 0; i-- {
+		err = multierr.Combine(err, receivers[i].Shutdown(ctx))
+	}
+	return err
+}
+
+func loadReceiverConfig(
 	factory rcvr.Factory,
 	receiver receiverConfig,
-) (component.Config, string, error) {
+) (component.Config, error) {
 	receiverCfg := factory.CreateDefaultConfig()
 	if err := component.UnmarshalConfig(confmap.NewFromStringMap(receiver.config), receiverCfg); err != nil {
-		return nil, "", fmt.Errorf("failed to load %q subreceiver config: %w", receiver.id.String(), err)
+		return nil, fmt.Errorf("failed to load %q subreceiver config: %w", receiver.id.String(), err)
 	}
-	return receiverCfg, "", nil
+	return receiverCfg, nil
 }
 
 // createLogsRuntimeReceiver creates a receiver that is discovered at runtime.
 func (run *receiverRunner) createLogsRuntimeReceiver(
+	ctx context.Context,
 	factory rcvr.Factory,
 	id component.ID,
 	cfg component.Config,
 	nextConsumer consumer.Logs,
-) (rcvr.Logs, error) {
+) (_ rcvr.Logs, err error) {
+	ctx, span := run.tracer.Start(ctx, "create_receiver", trace.WithAttributes(attribute.String("signal", "logs")))
+	defer func() {
+		// A receiver not supporting the signal is not a failure.
+		if errors.Is(err, component.ErrDataTypeIsNotSupported) {
+			span.End()
+			return
+		}
+		endSpan(span, err)
+	}()
+
 	runParams := run.params
 	runParams.Logger = runParams.Logger.With(zap.String("name", id.String()))
 	runParams.ID = id
-	return factory.CreateLogsReceiver(context.Background(), runParams, cfg, nextConsumer)
+	return factory.CreateLogsReceiver(ctx, runParams, cfg, nextConsumer)
 }
 
 // createMetricsRuntimeReceiver creates a receiver that is discovered at runtime.
 func (run *receiverRunner) createMetricsRuntimeReceiver(
+	ctx context.Context,
 	factory rcvr.Factory,
 	id component.ID,
 	cfg component.Config,
 	nextConsumer consumer.Metrics,
-) (rcvr.Metrics, error) {
+) (_ rcvr.Metrics, err error) {
+	ctx, span := run.tracer.Start(ctx, "create_receiver", trace.WithAttributes(attribute.String("signal", "metrics")))
+	defer func() {
+		// A receiver not supporting the signal is not a failure.
+		if errors.Is(err, component.ErrDataTypeIsNotSupported) {
+			span.End()
+			return
+		}
+		endSpan(span, err)
+	}()
+
 	runParams := run.params
 	runParams.Logger = runParams.Logger.With(zap.String("name", id.String()))
 	runParams.ID = id
-	return factory.CreateMetricsReceiver(context.Background(), runParams, cfg, nextConsumer)
+	return factory.CreateMetricsReceiver(ctx, runParams, cfg, nextConsumer)
 }
 
 // createTracesRuntimeReceiver creates a receiver that is discovered at runtime.
 func (run *receiverRunner) createTracesRuntimeReceiver(
+	ctx context.Context,
 	factory rcvr.Factory,
 	id component.ID,
 	cfg component.Config,
 	nextConsumer consumer.Traces,
-) (rcvr.Traces, error) {
+) (_ rcvr.Traces, err error) {
+	ctx, span := run.tracer.Start(ctx, "create_receiver", trace.WithAttributes(attribute.String("signal", "traces")))
+	defer func() {
+		// A receiver not supporting the signal is not a failure.
+		if errors.Is(err, component.ErrDataTypeIsNotSupported) {
+			span.End()
+			return
+		}
+		endSpan(span, err)
+	}()
+
 	runParams := run.params
 	runParams.Logger = runParams.Logger.With(zap.String("name", id.String()))
 	runParams.ID = id
-	return factory.CreateTracesReceiver(context.Background(), runParams, cfg, nextConsumer)
+	return factory.CreateTracesReceiver(ctx, runParams, cfg, nextConsumer)
+}
+
+func subreceiverAttribute(id component.ID) attribute.KeyValue {
+	return attribute.String("subreceiver", id.String())
 }
 
 var _ component.Component = (*wrappedReceiver)(nil)
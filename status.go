@@ -0,0 +1,109 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package leaderreceivercreator
+
+import (
+	"sync"
+
+	"go.opentelemetry.io/collector/component"
+)
+
+// statusReporter reports the component status of the receiver creator, so that the health check reflects
+// failed lease renewals, an unreachable backend and subreceivers that fail to start. Whether the instance is
+// the leader is reported by the leader_receiver_creator_is_leader metric.
+type statusReporter struct {
+	report func(*component.StatusEvent)
+
+	lock sync.Mutex
+	// renewFailing is true if the last lease renewal failed.
+	renewFailing bool
+	// backendFailing is true if the backend could not be reached on the last attempt.
+	backendFailing bool
+	// startFailing is true while the subreceivers fail to start.
+	startFailing bool
+	// permanent is true once a permanent error is reported, which no other status can follow.
+	permanent bool
+}
+
+func newStatusReporter(set component.TelemetrySettings) *statusReporter {
+	return &statusReporter{report: set.ReportStatus}
+}
+
+// leadershipChanged reports StatusOK when the leadership is acquired or lost, clearing a recoverable error
+// left by failed renewals of a previous term.
+func (sr *statusReporter) leadershipChanged() {
+	sr.lock.Lock()
+	defer sr.lock.Unlock()
+
+	if !sr.renewFailing {
+		return
+	}
+	sr.renewFailing = false
+	if !sr.failingLocked() {
+		sr.reportLocked(component.NewStatusEvent(component.StatusOK))
+	}
+}
+
+// leaseRenewed reports a recoverable error when the renewals of the lease start failing and StatusOK once
+// they succeed again.
+func (sr *statusReporter) leaseRenewed(err error) {
+	sr.lock.Lock()
+	defer sr.lock.Unlock()
+
+	sr.setFailingLocked(&sr.renewFailing, err)
+}
+
+// backendReached reports a recoverable error while the backend cannot be reached, e.g. because the
+// Kubernetes API server is unavailable, and StatusOK once it is reached again.
+func (sr *statusReporter) backendReached(err error) {
+	sr.lock.Lock()
+	defer sr.lock.Unlock()
+
+	sr.setFailingLocked(&sr.backendFailing, err)
+}
+
+// subreceiversFailing reports a recoverable error while the subreceivers fail to start and StatusOK once
+// they are started or stopped.
+func (sr *statusReporter) subreceiversFailing(err error) {
+	sr.lock.Lock()
+	defer sr.lock.Unlock()
+
+	sr.setFailingLocked(&sr.startFailing, err)
+}
+
+// subreceiversFailedPermanently reports a permanent error if a subreceiver cannot be created or the restarts
+// are given up.
+func (sr *statusReporter) subreceiversFailedPermanently(err error) {
+	sr.lock.Lock()
+	defer sr.lock.Unlock()
+
+	sr.startFailing = true
+	sr.reportLocked(component.NewPermanentErrorEvent(err))
+	sr.permanent = true
+}
+
+// setFailingLocked reports a recoverable error when failing turns true and StatusOK once neither the
+// renewals, the backend nor the subreceivers are failing anymore.
+func (sr *statusReporter) setFailingLocked(failing *bool, err error) {
+	if *failing == (err != nil) {
+		return
+	}
+	*failing = err != nil
+	if err != nil {
+		sr.reportLocked(component.NewRecoverableErrorEvent(err))
+	} else if !sr.failingLocked() {
+		sr.reportLocked(component.NewStatusEvent(component.StatusOK))
+	}
+}
+
+func (sr *statusReporter) failingLocked() bool {
+	return sr.renewFailing || sr.backendFailing || sr.startFailing
+}
+
+func (sr *statusReporter) reportLocked(event *component.StatusEvent) {
+	if sr.report == nil || sr.permanent {
+		return
+	}
+	sr.report(event)
+}
@@ -0,0 +1,117 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package leaderreceivercreator
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+)
+
+// statusRecorder records the reported component statuses.
+type statusRecorder struct {
+	lock     sync.Mutex
+	statuses []component.Status
+}
+
+func (r *statusRecorder) report(event *component.StatusEvent) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.statuses = append(r.statuses, event.Status())
+}
+
+func (r *statusRecorder) reported() []component.Status {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return append([]component.Status(nil), r.statuses...)
+}
+
+func TestStatusReporter(t *testing.T) {
+	recorder := &statusRecorder{}
+	sr := &statusReporter{report: recorder.report}
+
+	sr.leadershipChanged()
+	sr.leaseRenewed(nil)
+	assert.Empty(t, recorder.reported())
+
+	sr.leaseRenewed(errors.New("timeout"))
+	sr.leaseRenewed(errors.New("timeout"))
+	sr.leaseRenewed(nil)
+	sr.leaseRenewed(errors.New("timeout"))
+	sr.leadershipChanged()
+	assert.Equal(t, []component.Status{
+		component.StatusRecoverableError,
+		component.StatusOK,
+		component.StatusRecoverableError,
+		component.StatusOK,
+	}, recorder.reported())
+
+	// The leadership changes do not clear the error while the subreceivers fail to start.
+	sr.subreceiversFailing(errors.New("failed to start"))
+	sr.subreceiversFailing(errors.New("failed to start"))
+	sr.leadershipChanged()
+	sr.subreceiversFailing(nil)
+	assert.Equal(t, []component.Status{
+		component.StatusRecoverableError,
+		component.StatusOK,
+	}, recorder.reported()[4:])
+}
+
+func TestStatusReporterBackendReached(t *testing.T) {
+	recorder := &statusRecorder{}
+	sr := &statusReporter{report: recorder.report}
+
+	sr.backendReached(errors.New("connection refused"))
+	sr.backendReached(errors.New("connection refused"))
+	sr.leaseRenewed(errors.New("timeout"))
+	sr.leaseRenewed(nil)
+	// The leadership changes do not clear the error while the backend cannot be reached.
+	sr.leadershipChanged()
+	sr.backendReached(nil)
+	assert.Equal(t, []component.Status{
+		component.StatusRecoverableError,
+		component.StatusRecoverableError,
+		component.StatusOK,
+	}, recorder.reported())
+}
+
+func TestSubreceiverStartFailureStatus(t *testing.T) {
+	ler := newTestReceiverCreator(t.Name())
+	recorder := &statusRecorder{}
+	ler.params.TelemetrySettings.ReportStatus = recorder.report
+	ler.cfg.subreceiverConfigs = append(ler.cfg.subreceiverConfigs,
+		receiverConfig{id: component.NewID(failingType), config: map[string]any{}})
+
+	require.NoError(t, ler.Start(context.Background(), nopHost{Host: componenttest.NewNopHost()}))
+	defer func() {
+		require.NoError(t, ler.Shutdown(context.Background()))
+	}()
+
+	assert.Eventually(t, func() bool {
+		statuses := recorder.reported()
+		return len(statuses) == 1 && statuses[0] == component.StatusRecoverableError
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestStatusReporterPermanentError(t *testing.T) {
+	recorder := &statusRecorder{}
+	sr := &statusReporter{report: recorder.report}
+
+	sr.subreceiversFailing(errors.New("failed to start"))
+	sr.subreceiversFailedPermanently(errors.New("failed to start"))
+	// No status follows a permanent error.
+	sr.subreceiversFailing(nil)
+	sr.leaseRenewed(errors.New("timeout"))
+	assert.Equal(t, []component.Status{
+		component.StatusRecoverableError,
+		component.StatusPermanentError,
+	}, recorder.reported())
+}
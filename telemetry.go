@@ -0,0 +1,146 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package leaderreceivercreator
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/receiver"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/skhalash/leaderreceivercreator/internal/metadata"
+)
+
+// leaderTelemetry records the self-telemetry of the leadership and of the subreceivers. All measurements are
+// tagged with the ID of the receiver creator and the identity of its candidate.
+type leaderTelemetry struct {
+	builder *metadata.TelemetryBuilder
+	attrs   metric.MeasurementOption
+
+	lock               sync.Mutex
+	leading            bool
+	subreceiverRunning int64
+	// withoutLeadership is true while the subreceivers run without the leadership.
+	withoutLeadership bool
+	// vacantSince is the time the leadership was observed vacant, zero if it is held.
+	vacantSince time.Time
+}
+
+func newLeaderTelemetry(params receiver.CreateSettings, identity string) (*leaderTelemetry, error) {
+	attrs := attribute.NewSet(
+		attribute.String("receiver", params.ID.String()),
+		attribute.String("identity", identity),
+	)
+	lt := &leaderTelemetry{attrs: metric.WithAttributeSet(attrs)}
+	builder, err := metadata.NewTelemetryBuilder(params.TelemetrySettings,
+		metadata.WithAttributeSet(attrs),
+		metadata.WithLeaderReceiverCreatorIsLeaderCallback(lt.isLeader),
+		metadata.WithLeaderReceiverCreatorSubreceiverRunningCallback(lt.running),
+		metadata.WithLeaderReceiverCreatorRunningWithoutLeadershipCallback(lt.runningWithoutLeadership),
+	)
+	if err != nil {
+		return nil, err
+	}
+	lt.builder = builder
+	return lt, nil
+}
+
+func (lt *leaderTelemetry) isLeader() int64 {
+	lt.lock.Lock()
+	defer lt.lock.Unlock()
+	if lt.leading {
+		return 1
+	}
+	return 0
+}
+
+func (lt *leaderTelemetry) running() int64 {
+	lt.lock.Lock()
+	defer lt.lock.Unlock()
+	return lt.subreceiverRunning
+}
+
+func (lt *leaderTelemetry) runningWithoutLeadership() int64 {
+	lt.lock.Lock()
+	defer lt.lock.Unlock()
+	if lt.withoutLeadership {
+		return 1
+	}
+	return 0
+}
+
+func (lt *leaderTelemetry) setRunningWithoutLeadership(running bool) {
+	lt.lock.Lock()
+	defer lt.lock.Unlock()
+	lt.withoutLeadership = running
+}
+
+// setLeading records a leadership transition if the leadership state changes.
+func (lt *leaderTelemetry) setLeading(leading bool) {
+	lt.lock.Lock()
+	defer lt.lock.Unlock()
+
+	if lt.leading == leading {
+		return
+	}
+	lt.leading = leading
+	lt.builder.LeaderReceiverCreatorLeadershipTransitions.Add(context.Background(), 1, lt.attrs)
+}
+
+func (lt *leaderTelemetry) setSubreceiversRunning(count int) {
+	lt.lock.Lock()
+	defer lt.lock.Unlock()
+	lt.subreceiverRunning = int64(count)
+}
+
+func (lt *leaderTelemetry) subreceiverStartFailed() {
+	lt.builder.LeaderReceiverCreatorSubreceiverStartFailures.Add(context.Background(), 1, lt.attrs)
+}
+
+func (lt *leaderTelemetry) leaseRenewed(latency time.Duration) {
+	lt.builder.LeaderReceiverCreatorLeaseRenewLatency.Record(context.Background(), latency.Seconds(), lt.attrs)
+}
+
+// newLeader records the failover gap when this replica takes over the leadership after it was observed vacant.
+// The gap is not known if the previous leader was not observed to give up the leadership, e.g. because it
+// crashed and the backend does not report expired leases.
+func (lt *leaderTelemetry) newLeader(leader, identity string) {
+	lt.lock.Lock()
+	defer lt.lock.Unlock()
+
+	if leader == "" {
+		if lt.vacantSince.IsZero() {
+			lt.vacantSince = time.Now()
+		}
+		return
+	}
+	if leader == identity && !lt.vacantSince.IsZero() {
+		lt.builder.LeaderReceiverCreatorFailoverGapDuration.Record(context.Background(), time.Since(lt.vacantSince).Seconds(), lt.attrs)
+	}
+	lt.vacantSince = time.Time{}
+}
+
+// shutdown resets the observed state, so that the gauges report zero once the receiver creator is shut down.
+func (lt *leaderTelemetry) shutdown() {
+	lt.lock.Lock()
+	defer lt.lock.Unlock()
+	lt.leading = false
+	lt.subreceiverRunning = 0
+	lt.withoutLeadership = false
+	lt.vacantSince = time.Time{}
+}
+
+// endSpan records err on the span, if any, and ends it.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
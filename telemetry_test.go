@@ -0,0 +1,182 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package leaderreceivercreator
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// withMetricReader makes the receiver creator report its self-telemetry to the returned reader.
+func withMetricReader(ler *leaderReceiverCreator) *sdkmetric.ManualReader {
+	reader := sdkmetric.NewManualReader()
+	ler.params.TelemetrySettings.MeterProvider = sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	return reader
+}
+
+// withSpanRecorder makes the receiver creator report its spans to the returned recorder.
+func withSpanRecorder(ler *leaderReceiverCreator) *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+	ler.params.TelemetrySettings.TracerProvider = sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	return recorder
+}
+
+// endedSpans returns the ended spans with the given name.
+func endedSpans(recorder *tracetest.SpanRecorder, name string) []sdktrace.ReadOnlySpan {
+	var spans []sdktrace.ReadOnlySpan
+	for _, span := range recorder.Ended() {
+		if span.Name() == name {
+			spans = append(spans, span)
+		}
+	}
+	return spans
+}
+
+// metricValue returns the value of the int64 sum or gauge metric, or the count of the histogram metric.
+func metricValue(t *testing.T, reader *sdkmetric.ManualReader, name string) (int64, bool) {
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &rm))
+
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name != name {
+				continue
+			}
+			switch data := m.Data.(type) {
+			case metricdata.Sum[int64]:
+				return data.DataPoints[0].Value, true
+			case metricdata.Gauge[int64]:
+				return data.DataPoints[0].Value, true
+			case metricdata.Histogram[float64]:
+				return int64(data.DataPoints[0].Count), true
+			}
+		}
+	}
+	return 0, false
+}
+
+func TestLeadershipTelemetry(t *testing.T) {
+	host := nopHost{Host: componenttest.NewNopHost()}
+	leader := newTestReceiverCreator(t.Name())
+	leaderReader := withMetricReader(leader)
+	follower := newTestReceiverCreator(t.Name())
+	followerReader := withMetricReader(follower)
+
+	require.NoError(t, leader.Start(context.Background(), host))
+	require.Eventually(t, leader.subReceiverRunning, 5*time.Second, 10*time.Millisecond)
+	require.NoError(t, follower.Start(context.Background(), host))
+	defer func() {
+		require.NoError(t, follower.Shutdown(context.Background()))
+	}()
+	assert.Never(t, follower.subReceiverRunning, 100*time.Millisecond, 10*time.Millisecond)
+
+	value, _ := metricValue(t, leaderReader, "leader_receiver_creator_is_leader")
+	assert.Equal(t, int64(1), value)
+	value, _ = metricValue(t, leaderReader, "leader_receiver_creator_subreceiver_running")
+	assert.Equal(t, int64(2), value)
+	value, _ = metricValue(t, leaderReader, "leader_receiver_creator_leadership_transitions")
+	assert.Equal(t, int64(1), value)
+	value, _ = metricValue(t, followerReader, "leader_receiver_creator_is_leader")
+	assert.Equal(t, int64(0), value)
+
+	require.NoError(t, leader.Shutdown(context.Background()))
+	require.Eventually(t, follower.subReceiverRunning, 5*time.Second, 10*time.Millisecond)
+
+	value, _ = metricValue(t, followerReader, "leader_receiver_creator_is_leader")
+	assert.Equal(t, int64(1), value)
+	value, ok := metricValue(t, followerReader, "leader_receiver_creator_failover_gap_duration")
+	assert.True(t, ok)
+	assert.Equal(t, int64(1), value)
+}
+
+func TestShutdownResetsTelemetry(t *testing.T) {
+	ler := newTestReceiverCreator(t.Name())
+	reader := withMetricReader(ler)
+
+	require.NoError(t, ler.Start(context.Background(), nopHost{Host: componenttest.NewNopHost()}))
+	require.Eventually(t, ler.subReceiverRunning, 5*time.Second, 10*time.Millisecond)
+
+	// The telemetry is reset even if the election loop does not exit in time.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_ = ler.Shutdown(ctx)
+
+	value, _ := metricValue(t, reader, "leader_receiver_creator_is_leader")
+	assert.Equal(t, int64(0), value)
+	value, _ = metricValue(t, reader, "leader_receiver_creator_subreceiver_running")
+	assert.Equal(t, int64(0), value)
+}
+
+func TestSubreceiverStartFailureTelemetry(t *testing.T) {
+	ler := newTestReceiverCreator(t.Name())
+	reader := withMetricReader(ler)
+	ler.cfg.subreceiverConfigs = append(ler.cfg.subreceiverConfigs,
+		receiverConfig{id: component.NewID(failingType), config: map[string]any{}})
+
+	require.NoError(t, ler.Start(context.Background(), nopHost{Host: componenttest.NewNopHost()}))
+	defer func() {
+		require.NoError(t, ler.Shutdown(context.Background()))
+	}()
+
+	assert.Eventually(t, func() bool {
+		value, _ := metricValue(t, reader, "leader_receiver_creator_subreceiver_start_failures")
+		return value == 1
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestLeadershipSpans(t *testing.T) {
+	ler := newTestReceiverCreator(t.Name())
+	recorder := withSpanRecorder(ler)
+
+	require.NoError(t, ler.Start(context.Background(), nopHost{Host: componenttest.NewNopHost()}))
+	require.Eventually(t, ler.subReceiverRunning, 5*time.Second, 10*time.Millisecond)
+	require.NoError(t, ler.Shutdown(context.Background()))
+
+	assert.Len(t, endedSpans(recorder, "resolve_subreceiver"), 2)
+	campaigns := endedSpans(recorder, "campaign")
+	require.NotEmpty(t, campaigns)
+	assert.Equal(t, "Leadership acquired", campaigns[0].Events()[0].Name)
+
+	starts := endedSpans(recorder, "start_subreceivers")
+	require.Len(t, starts, 1)
+	assert.Equal(t, campaigns[0].SpanContext().TraceID(), starts[0].SpanContext().TraceID())
+	assert.Len(t, endedSpans(recorder, "start_subreceiver"), 2)
+	assert.Len(t, endedSpans(recorder, "create_receiver"), 6)
+	assert.Len(t, endedSpans(recorder, "start_receiver"), 2)
+	assert.Len(t, endedSpans(recorder, "stop_subreceivers"), 1)
+}
+
+func TestSubreceiverStartFailureSpans(t *testing.T) {
+	ler := newTestReceiverCreator(t.Name())
+	recorder := withSpanRecorder(ler)
+	ler.cfg.subreceiverConfigs = append(ler.cfg.subreceiverConfigs,
+		receiverConfig{id: component.NewID(failingType), config: map[string]any{}})
+
+	require.NoError(t, ler.Start(context.Background(), nopHost{Host: componenttest.NewNopHost()}))
+	defer func() {
+		require.NoError(t, ler.Shutdown(context.Background()))
+	}()
+
+	require.Eventually(t, func() bool {
+		return len(endedSpans(recorder, "start_subreceivers")) > 0
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, codes.Error, endedSpans(recorder, "start_subreceivers")[0].Status().Code)
+	for _, span := range endedSpans(recorder, "start_subreceiver") {
+		if span.Attributes()[0].Value.AsString() == "failing" {
+			assert.Equal(t, codes.Error, span.Status().Code)
+			assert.Equal(t, "exception", span.Events()[0].Name)
+		}
+	}
+}